@@ -0,0 +1,136 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// StorageMode selects how the backup target storage is provisioned.
+type StorageMode string
+
+const (
+	// StoragePVC backs the backup target with a PersistentVolumeClaim.
+	StoragePVC StorageMode = "pvc"
+	// StorageHostPath backs the backup target with a hostPath volume.
+	StorageHostPath StorageMode = "hostPath"
+)
+
+// SinkMode selects where the finished tarball is shipped to.
+type SinkMode string
+
+const (
+	// SinkLocal leaves the tarball on the jump host / local disk.
+	SinkLocal SinkMode = "local"
+	// SinkS3 uploads the tarball to an S3-compatible object store.
+	SinkS3 SinkMode = "s3"
+)
+
+// Phase describes where an EtcdBackupRequest is in its lifecycle.
+type Phase string
+
+const (
+	PhasePending   Phase = "Pending"
+	PhaseRunning   Phase = "Running"
+	PhaseSucceeded Phase = "Succeeded"
+	PhaseFailed    Phase = "Failed"
+)
+
+// EtcdBackupRequestSpec is the desired state of an etcd backup, mirroring
+// the flags already accepted by the etcd-backup CLI so the controller can
+// drive the same createServiceAccount/createMissingPVCs/waitForJobComplete/
+// pullBackupLocal functions that the CLI uses directly.
+type EtcdBackupRequestSpec struct {
+	// Node is the control-plane node to run the backup against. If empty,
+	// the controller picks the first node labeled node-role.kubernetes.io/master.
+	Node string `json:"node,omitempty"`
+
+	// Storage selects how the backup target PVC/volume is provisioned.
+	Storage StorageMode `json:"storage,omitempty"`
+
+	// Sink selects where the finished tarball ends up.
+	Sink SinkMode `json:"sink,omitempty"`
+
+	// Schedule is an optional cron expression; when set the controller
+	// reconciles a CronJob instead of a one-shot Job.
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// EtcdBackupRequestStatus is the observed state of an etcd backup.
+type EtcdBackupRequestStatus struct {
+	Phase           Phase  `json:"phase,omitempty"`
+	TarballLocation string `json:"tarballLocation,omitempty"`
+	TarballSizeByte int64  `json:"tarballSizeBytes,omitempty"`
+	SHA256          string `json:"sha256,omitempty"`
+	Message         string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// EtcdBackupRequest is the Schema for declaring a repeatable, scheduled etcd
+// backup instead of invoking the etcd-backup CLI by hand.
+type EtcdBackupRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EtcdBackupRequestSpec   `json:"spec,omitempty"`
+	Status EtcdBackupRequestStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EtcdBackupRequestList contains a list of EtcdBackupRequest.
+type EtcdBackupRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EtcdBackupRequest `json:"items"`
+}
+
+// GroupVersion is the API group/version this package registers types under.
+var GroupVersion = schema.GroupVersion{Group: "backup.stratus.io", Version: "v1"}
+
+// SchemeBuilder collects the funcs to add this package's types to a Scheme.
+var SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+
+// AddToScheme adds this package's types to the given Scheme.
+var AddToScheme = SchemeBuilder.AddToScheme
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(GroupVersion,
+		&EtcdBackupRequest{},
+		&EtcdBackupRequestList{},
+	)
+	metav1.AddToGroupVersion(scheme, GroupVersion)
+	return nil
+}
+
+// DeepCopyObject is hand-written here instead of being generated by
+// controller-gen since this repo has no Makefile/codegen wiring yet; it
+// should be replaced by a generated zz_generated.deepcopy.go once that's added.
+func (in *EtcdBackupRequest) DeepCopyObject() runtime.Object {
+	out := new(EtcdBackupRequest)
+	*out = *in
+	out.ObjectMeta = *in.ObjectMeta.DeepCopy()
+	return out
+}
+
+// DeepCopyObject is hand-written for the same reason as EtcdBackupRequest's.
+func (in *EtcdBackupRequestList) DeepCopyObject() runtime.Object {
+	out := new(EtcdBackupRequestList)
+	*out = *in
+	out.ListMeta = *in.ListMeta.DeepCopy()
+	if in.Items != nil {
+		out.Items = make([]EtcdBackupRequest, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+	return out
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *EtcdBackupRequest) DeepCopyInto(out *EtcdBackupRequest) {
+	*out = *in
+	out.ObjectMeta = *in.ObjectMeta.DeepCopy()
+}
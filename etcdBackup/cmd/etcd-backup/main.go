@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"os/user"
+	"syscall"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/stratus-ss/k8s-tools/etcdBackup/pkg/etcdbackup"
+)
+
+func main() {
+	backupPodImage := flag.String("backup-pod-image-version", "v4.9", "The version of the ose-client to use")
+	kubeConfigFile := flag.String("kube-config", "", "Full path to kubeconfig")
+	usePVC := flag.Bool("use-pvc", true, "Does the backup pod use a PVC? If not, dump it backup to local directory")
+	localBackupDirectory := flag.String("local-backup-dir", "/tmp", "Full LOCAL path to put backup")
+	etcdBackupProject := flag.String("etcd-backup-project", "ocp-etcd-backup", "Which project to create etcd backup pods")
+	nfsServer := flag.String("nfs-server", "", "IP or Hostname of the NFS Server")
+	nfsPath := flag.String("nfs-path", "", "NFS Path to save backups to")
+	debug := flag.Bool("debug", false, "Turns on some debug messages")
+	taintName := flag.String("taint", "node-role.kubernetes.io/master", "Specify a taint to ignore so the pod can run on the control plane")
+	useNFS := flag.Bool("use-nfs", false, "Denotes whether the PVC uses NFS or not")
+	nfsPVName := flag.String("nfs-volume-name", "etcd-nfs-backup-vol", "NFS Path to save backups to")
+	nfsPVCName := flag.String("nfs-claim-name", "", "NFS PVC claim name which binds to a persistent volume")
+	dynamicPVCName := flag.String("dynamic-claim-name", "", "Name of the dynamic PVC")
+	useDynamicStorage := flag.Bool("use-dynamic-storage", false, "Create a PVC for dynamic storage")
+	ocpBinaryPath := flag.String("oc-binary-path", "", "Path to the OC cli binary, only used with --legacy-oc-debug")
+	snapshotMode := flag.String("snapshot-mode", "oc-debug", "Backup mode to use: 'oc-debug' for the tarball path, 'csi' for a CSI VolumeSnapshot of the etcd data PVC")
+	snapshotClassName := flag.String("snapshot-class", "", "VolumeSnapshotClass to use when --snapshot-mode=csi")
+	retainSnapshots := flag.Int("retain", 5, "Number of VolumeSnapshots to retain when --snapshot-mode=csi, older ones are pruned")
+	backupSink := flag.String("backup-sink", "local", "Where to send the local tarball after it is pulled: 'local' or 's3'")
+	s3Bucket := flag.String("s3-bucket", "", "Bucket name to upload the tarball to when --backup-sink=s3")
+	s3Endpoint := flag.String("s3-endpoint", "", "Endpoint override for S3-compatible stores (MinIO, Ceph RGW) when --backup-sink=s3")
+	s3Region := flag.String("s3-region", "us-east-1", "Region to use when --backup-sink=s3")
+	s3KeyPrefix := flag.String("s3-key-prefix", "", "Key prefix to upload the tarball under, e.g. cluster/<name>/etcd")
+	s3SSECKey := flag.String("s3-sse-c-key", "", "Base64 SSE-C key to encrypt the uploaded tarball with, instead of default SSE-S3")
+	s3RetainObjects := flag.Int("s3-retain", -1, "Number of tarballs to retain under the S3 key prefix, older ones are pruned. -1 disables pruning")
+	legacyOcDebug := flag.Bool("legacy-oc-debug", false, "Pull the backup tarball by shelling out to `oc debug node/` instead of using a native debug pod")
+	timeout := flag.Duration("timeout", 4*time.Minute, "Overall timeout for waiting on the backup Job and PVC binding")
+	pollInterval := flag.Duration("poll-interval", 10*time.Second, "Base poll interval for the exponential-backoff waits on the backup Job and PVC binding")
+	dryRun := flag.Bool("dry-run", false, "Print a diff of what would change instead of mutating the cluster")
+	schedule := flag.String("schedule", "", "Cron expression for recurring backups. When set, reconciles a CronJob instead of running a one-shot Job")
+	keepLast := flag.Int("keep-last", 0, "Number of most recent tarballs to keep when --schedule is set, 0 disables this check")
+	keepWithin := flag.Duration("keep-within", 0, "Delete tarballs older than this duration when --schedule is set, 0 disables this check")
+	backupTarget := flag.String("backup-target", "", "Where to ship the tarball from inside the backup pod: pvc://<claim>, s3://<bucket>/<prefix>, azblob://<container>/<prefix>, restic://<repository-url>. Supersedes --use-pvc/--use-nfs/--use-dynamic-storage when set")
+	backupTargetSecret := flag.String("backup-target-secret", "", "Name of the Secret in the backup project to source credentials from for s3/azblob/restic --backup-target backends")
+	reclaimPolicy := flag.String("reclaim-policy", "Retain", "Reclaim policy to set on the static NFS PersistentVolume (Retain or Delete)")
+	restoreSnapshotName := flag.String("restore-from-snapshot", "", "Name of a VolumeSnapshot to restore from. When set, provisions --restore-pvc-name from it and exits instead of taking a backup")
+	restorePVCName := flag.String("restore-pvc-name", "", "Name of the PVC to create when --restore-from-snapshot is set")
+	restoreVolumeSize := flag.String("restore-volume-size", "10Gi", "Size to request for the PVC created by --restore-from-snapshot")
+	restoreStorageClassName := flag.String("restore-storage-class", "", "Storage class to provision the PVC created by --restore-from-snapshot from")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	// do error checking based on if PVCs are being used and if so, which type
+	if *usePVC {
+		if *useNFS {
+			if *nfsServer == "" {
+				flag.Usage()
+				fmt.Println("")
+				fmt.Println("!!! NFS Server is required if using a PVC !!!")
+				os.Exit(1)
+			}
+			if *nfsPath == "" {
+				flag.Usage()
+				fmt.Println("")
+				fmt.Println("!!! NFS Path is required if using a PVC !!!")
+				os.Exit(1)
+			}
+			if *nfsPVCName == "" {
+				*nfsPVCName = "etcd-nfs-backup-claim"
+				if *debug {
+					fmt.Printf("    (DEBUG)    --->     No Claim name speicified!\n")
+					fmt.Printf("    (DEBUG)    --->     Using: %s\n", *nfsPVCName)
+				}
+			}
+		}
+		if *useDynamicStorage {
+			if *dynamicPVCName == "" {
+				*dynamicPVCName = "etcd-dynamic-backup-claim"
+				if *debug {
+					fmt.Printf("    (DEBUG)    --->     No Claim name speicified!\n")
+					fmt.Printf("    (DEBUG)    --->     Using: %s\n", *dynamicPVCName)
+				}
+			}
+		}
+	}
+
+	// This is a temporary holder until I find a better way to pass in this config
+	// If no kubeconfig is passed in, attempt to find it in a default location
+	if *kubeConfigFile == "" {
+		fmt.Println("No kubeconfig attempting to use ~/.kube/auth/kubeconfig")
+		userName, _ := user.Current()
+		kubePath := fmt.Sprintf("/home/%s/.kube/auth/kubeconfig", userName)
+		if _, err := os.Stat(kubePath); errors.Is(err, os.ErrNotExist) {
+			panic("Kubeconfig was not passed in and does not exist in the default location... cannot continue!")
+		}
+		*kubeConfigFile = "${USER}/.kube/auth/kubeconfig"
+	}
+
+	fmt.Println("Connecting to cluster")
+	if *debug {
+		fmt.Printf("    (DEBUG)    --->     Connecting using kubeconfig: %s\n", *kubeConfigFile)
+	}
+	restConfig, err := clientcmd.BuildConfigFromFlags("", *kubeConfigFile)
+	if err != nil {
+		panic(err)
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	result, err := etcdbackup.Run(ctx, client, etcdbackup.Options{
+		RestConfig:              restConfig,
+		Namespace:               *etcdBackupProject,
+		ImageURL:                "registry.redhat.io/openshift4/ose-cli:" + *backupPodImage,
+		ServiceAccountName:      "openshift-backup",
+		TaintName:               *taintName,
+		UsePVC:                  *usePVC,
+		UseNFS:                  *useNFS,
+		NFSServer:               *nfsServer,
+		NFSPath:                 *nfsPath,
+		NFSVolumeName:           *nfsPVName,
+		NFSClaimName:            *nfsPVCName,
+		ReclaimPolicy:           corev1.PersistentVolumeReclaimPolicy(*reclaimPolicy),
+		UseDynamicStorage:       *useDynamicStorage,
+		DynamicClaimName:        *dynamicPVCName,
+		SnapshotMode:            *snapshotMode,
+		SnapshotClassName:       *snapshotClassName,
+		RetainSnapshots:         *retainSnapshots,
+		RestoreSnapshotName:     *restoreSnapshotName,
+		RestorePVCName:          *restorePVCName,
+		RestoreVolumeSize:       *restoreVolumeSize,
+		RestoreStorageClassName: *restoreStorageClassName,
+		BackupTarget:            *backupTarget,
+		BackupTargetSecret:      *backupTargetSecret,
+		Schedule:                *schedule,
+		KeepLast:                *keepLast,
+		KeepWithin:              *keepWithin,
+		BackupSink:              *backupSink,
+		LocalBackupDirectory:    *localBackupDirectory,
+		S3: etcdbackup.S3SinkOptions{
+			Bucket:      *s3Bucket,
+			EndpointURL: *s3Endpoint,
+			Region:      *s3Region,
+			KeyPrefix:   *s3KeyPrefix,
+			SSECKey:     *s3SSECKey,
+		},
+		S3RetainObjects: *s3RetainObjects,
+		LegacyOcDebug:   *legacyOcDebug,
+		OCPBinaryPath:   *ocpBinaryPath,
+		KubeConfigFile:  *kubeConfigFile,
+		DryRun:          *dryRun,
+		Debug:           *debug,
+		DebugHeader:     "    (DEBUG)    --->    ",
+		PollInterval:    *pollInterval,
+	})
+
+	if err != nil {
+		fmt.Printf("Backup did not complete successfully: %s\n", err)
+		os.Exit(1)
+	}
+	if result != nil && result.Hash != "" {
+		fmt.Printf("Verified snapshot: revision=%d hash=%s totalKeys=%d totalSizeBytes=%d\n", result.Revision, result.Hash, result.TotalKeys, result.TotalSizeBytes)
+	}
+	fmt.Println("Backup job complete")
+}
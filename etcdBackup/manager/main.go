@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	backupv1 "github.com/stratus-ss/k8s-tools/etcdBackup/api/v1"
+	"github.com/stratus-ss/k8s-tools/etcdBackup/controllers"
+)
+
+// manager runs the EtcdBackupRequest controller, giving users a declarative,
+// repeatable alternative to invoking the etcd-backup CLI by hand.
+func main() {
+	metricsAddr := flag.String("metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	probeAddr := flag.String("health-probe-bind-address", ":8081", "The address the health probe endpoint binds to.")
+	flag.Parse()
+
+	scheme := runtime.NewScheme()
+	if err := backupv1.AddToScheme(scheme); err != nil {
+		ctrl.Log.Error(err, "unable to add EtcdBackupRequest to scheme")
+		os.Exit(1)
+	}
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		Scheme:                 scheme,
+		Metrics:                metricsserver.Options{BindAddress: *metricsAddr},
+		HealthProbeBindAddress: *probeAddr,
+	})
+	if err != nil {
+		ctrl.Log.Error(err, "unable to start manager")
+		os.Exit(1)
+	}
+
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		ctrl.Log.Error(err, "unable to build clientset")
+		os.Exit(1)
+	}
+
+	reconciler := &controllers.EtcdBackupRequestReconciler{Clientset: clientset, RestConfig: mgr.GetConfig()}
+	if err := reconciler.SetupWithManager(mgr); err != nil {
+		ctrl.Log.Error(err, "unable to create EtcdBackupRequest controller")
+		os.Exit(1)
+	}
+
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		ctrl.Log.Error(err, "unable to set up health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+		ctrl.Log.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
+
+	ctrl.Log.Info("starting manager")
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+		ctrl.Log.Error(err, "problem running manager")
+		os.Exit(1)
+	}
+}
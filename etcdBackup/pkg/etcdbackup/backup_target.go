@@ -0,0 +1,192 @@
+package etcdbackup
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// BackupTarget knows how to ship the etcd backup tarball somewhere once the
+// cluster-backup.sh and tar steps inside the backup pod are done. Everything
+// up to "ship the tarball" is shared across backends (see createBackupPodWithTarget);
+// only this last step, and whatever volumes/credentials it needs, differ.
+type BackupTarget interface {
+	// Name identifies the backend in debug/log output.
+	Name() string
+	// Volumes returns any Volumes the pod spec needs to ship the tarball.
+	Volumes() []corev1.Volume
+	// VolumeMounts returns the mounts for the container running ShipCommand.
+	VolumeMounts() []corev1.VolumeMount
+	// Env returns backend-specific environment variables, e.g. a repo URL.
+	Env() []corev1.EnvVar
+	// EnvFrom returns the Secret (if any) credentials should be sourced from.
+	EnvFrom() []corev1.EnvFromSource
+	// ShipCommand returns the shell command, run once the tarball exists at
+	// tarballPath, that copies it to the target.
+	ShipCommand(tarballPath string) string
+}
+
+// NewBackupTarget parses a --backup-target URL into a BackupTarget
+// implementation. secretName, when non-empty, is mounted as the credentials
+// source for backends that need one (S3, Azure Blob, Restic).
+//
+//	pvc://<claim-name>              an existing PVC, mounted at /backups
+//	s3://<bucket>/<prefix>          shipped via `aws s3 cp`
+//	azblob://<container>/<prefix>  shipped via `az storage blob upload`
+//	restic://<repository-url>      shipped via `restic backup`
+func NewBackupTarget(targetURL string, secretName string) (BackupTarget, error) {
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --backup-target %q: %w", targetURL, err)
+	}
+
+	switch parsed.Scheme {
+	case "pvc":
+		if parsed.Host == "" {
+			return nil, fmt.Errorf("--backup-target=%q is missing a PVC name, expected pvc://<claim-name>", targetURL)
+		}
+		return &pvcTarget{claimName: parsed.Host}, nil
+	case "s3":
+		if parsed.Host == "" {
+			return nil, fmt.Errorf("--backup-target=%q is missing a bucket name, expected s3://<bucket>/<prefix>", targetURL)
+		}
+		return &s3Target{bucket: parsed.Host, prefix: strings.Trim(parsed.Path, "/"), secretName: secretName}, nil
+	case "azblob":
+		if parsed.Host == "" {
+			return nil, fmt.Errorf("--backup-target=%q is missing a container name, expected azblob://<container>/<prefix>", targetURL)
+		}
+		return &azureBlobTarget{container: parsed.Host, prefix: strings.Trim(parsed.Path, "/"), secretName: secretName}, nil
+	case "restic":
+		repoURL := strings.TrimPrefix(targetURL, "restic://")
+		if repoURL == "" {
+			return nil, fmt.Errorf("--backup-target=%q is missing a repository, expected restic://<repository-url>", targetURL)
+		}
+		return &resticTarget{repoURL: repoURL, secretName: secretName}, nil
+	default:
+		return nil, fmt.Errorf("unknown --backup-target scheme %q, expected one of pvc, s3, azblob, restic", parsed.Scheme)
+	}
+}
+
+// pvcTarget ships the tarball onto an existing PVC, the same behavior
+// createBackupPodWithPVC has always had for a single mounted claim.
+type pvcTarget struct {
+	claimName string
+}
+
+func (t *pvcTarget) Name() string { return "pvc" }
+
+func (t *pvcTarget) Volumes() []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name: "etcd-backup-mount",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: t.claimName},
+			},
+		},
+	}
+}
+
+func (t *pvcTarget) VolumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{{Name: "etcd-backup-mount", MountPath: "/backups"}}
+}
+
+func (t *pvcTarget) Env() []corev1.EnvVar { return nil }
+
+func (t *pvcTarget) EnvFrom() []corev1.EnvFromSource { return nil }
+
+func (t *pvcTarget) ShipCommand(tarballPath string) string {
+	return "cat " + tarballPath + " > /backups/backup_$(date +%Y-%m-%d_%H-%M_%Z).db.tgz"
+}
+
+// s3Target ships the tarball via the aws CLI, which is already present on
+// the ose-cli image this tool runs the backup pod from.
+type s3Target struct {
+	bucket     string
+	prefix     string
+	secretName string
+}
+
+func (t *s3Target) Name() string { return "s3" }
+
+func (t *s3Target) Volumes() []corev1.Volume { return nil }
+
+func (t *s3Target) VolumeMounts() []corev1.VolumeMount { return nil }
+
+func (t *s3Target) Env() []corev1.EnvVar { return nil }
+
+func (t *s3Target) EnvFrom() []corev1.EnvFromSource {
+	return secretEnvFrom(t.secretName)
+}
+
+func (t *s3Target) ShipCommand(tarballPath string) string {
+	key := t.bucket
+	if t.prefix != "" {
+		key = t.bucket + "/" + t.prefix
+	}
+	return fmt.Sprintf("aws s3 cp %s s3://%s/backup_$(date +%%Y-%%m-%%d_%%H-%%M_%%Z).db.tgz", tarballPath, key)
+}
+
+// azureBlobTarget ships the tarball via the az CLI. AZURE_STORAGE_ACCOUNT and
+// AZURE_STORAGE_KEY are expected to come from secretName.
+type azureBlobTarget struct {
+	container  string
+	prefix     string
+	secretName string
+}
+
+func (t *azureBlobTarget) Name() string { return "azblob" }
+
+func (t *azureBlobTarget) Volumes() []corev1.Volume { return nil }
+
+func (t *azureBlobTarget) VolumeMounts() []corev1.VolumeMount { return nil }
+
+func (t *azureBlobTarget) Env() []corev1.EnvVar { return nil }
+
+func (t *azureBlobTarget) EnvFrom() []corev1.EnvFromSource {
+	return secretEnvFrom(t.secretName)
+}
+
+func (t *azureBlobTarget) ShipCommand(tarballPath string) string {
+	blobName := "backup_$(date +%Y-%m-%d_%H-%M_%Z).db.tgz"
+	if t.prefix != "" {
+		blobName = t.prefix + "/" + blobName
+	}
+	return fmt.Sprintf("az storage blob upload --container-name %s --name %s --file %s", t.container, blobName, tarballPath)
+}
+
+// resticTarget ships the tarball into a restic repository. RESTIC_PASSWORD
+// (and any backend-specific credentials, e.g. AWS_ACCESS_KEY_ID for an s3:
+// repo URL) are expected to come from secretName.
+type resticTarget struct {
+	repoURL    string
+	secretName string
+}
+
+func (t *resticTarget) Name() string { return "restic" }
+
+func (t *resticTarget) Volumes() []corev1.Volume { return nil }
+
+func (t *resticTarget) VolumeMounts() []corev1.VolumeMount { return nil }
+
+func (t *resticTarget) Env() []corev1.EnvVar {
+	return []corev1.EnvVar{{Name: "RESTIC_REPOSITORY", Value: t.repoURL}}
+}
+
+func (t *resticTarget) EnvFrom() []corev1.EnvFromSource {
+	return secretEnvFrom(t.secretName)
+}
+
+func (t *resticTarget) ShipCommand(tarballPath string) string {
+	return "restic backup " + tarballPath
+}
+
+// secretEnvFrom is shared by every credential-based target: when no Secret
+// name was given there is nothing to source env vars from.
+func secretEnvFrom(secretName string) []corev1.EnvFromSource {
+	if secretName == "" {
+		return nil
+	}
+	return []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}}}}
+}
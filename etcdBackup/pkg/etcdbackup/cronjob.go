@@ -0,0 +1,131 @@
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/client-go/kubernetes"
+)
+
+// cronOwnerLabelKey marks the Jobs a CronJob spawns so reconcileScheduledBackup
+// can find them again without depending on Kubernetes' own job-name hashing.
+const cronOwnerLabelKey = "backup.stratus.io/owned-by"
+
+// globalConcurrentBackupJobsLimit mirrors k8up's GlobalConcurrentBackupJobsLimit:
+// only one Job owned by a given CronJob may be Active at a time, since two
+// backups running together would race on the retention cleanup below.
+const globalConcurrentBackupJobsLimit = 1
+
+// retentionScript returns a shell snippet appended to the backup container's
+// command that lists backup_*.db.tgz files in backupDir, sorts them by the
+// timestamp encoded in the filename (the backup_YYYY-MM-DD_HH-MM_TZ.db.tgz
+// format sorts lexicographically in chronological order), and removes
+// whatever falls outside the --keep-last/--keep-within window.
+func retentionScript(backupDir string, keepLast int, keepWithin time.Duration) string {
+	cmd := fmt.Sprintf("files=$(ls -1 %s/backup_*.db.tgz 2>/dev/null | sort)", backupDir)
+	if keepLast > 0 {
+		cmd += fmt.Sprintf(" && echo \"$files\" | head -n -%d | xargs -r rm -fv", keepLast)
+	}
+	if keepWithin > 0 {
+		cmd += fmt.Sprintf(" && find %s -maxdepth 1 -name 'backup_*.db.tgz' -mmin +%d -exec rm -fv {} \\;", backupDir, int(keepWithin.Minutes()))
+	}
+	return cmd
+}
+
+// buildBackupCronJob wraps a one-shot backup Job template (as produced by
+// createBackupPodWithPVC) into a CronJob that runs it on schedule and prunes
+// old tarballs under backupDir on every run.
+func buildBackupCronJob(cronJobName string, namespaceName string, backupJob *batchv1.Job, schedule string, backupDir string, keepLast int, keepWithin time.Duration) *batchv1.CronJob {
+	labels := map[string]string{cronOwnerLabelKey: cronJobName}
+
+	podSpec := *backupJob.Spec.Template.Spec.DeepCopy()
+	if len(podSpec.Containers) > 0 {
+		lastCommand := podSpec.Containers[0].Command
+		if len(lastCommand) > 0 {
+			lastCommand[len(lastCommand)-1] = lastCommand[len(lastCommand)-1] + " && " + retentionScript(backupDir, keepLast, keepWithin)
+		}
+	}
+
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cronJobName,
+			Namespace: namespaceName,
+			Labels:    labels,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          schedule,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: batchv1.JobSpec{
+					PodFailurePolicy: backupJob.Spec.PodFailurePolicy,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec:       podSpec,
+					},
+				},
+			},
+		},
+	}
+}
+
+// reconcileScheduledBackup creates or updates the CronJob that owns the
+// recurring backup schedule. It refuses to touch the CronJob while a Job it
+// previously spawned is still Active, so a slow-running backup is never
+// raced by a reconcile that changes the schedule or template out from
+// under it.
+func reconcileScheduledBackup(ctx context.Context, namespaceName string, cronJobName string, desired *batchv1.CronJob, dryRun bool, debug bool, debugHeader string, client kubernetes.Interface) error {
+	ownedJobs, listErr := client.BatchV1().Jobs(namespaceName).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", cronOwnerLabelKey, cronJobName),
+	})
+	if listErr != nil {
+		return listErr
+	}
+	active := 0
+	for _, job := range ownedJobs.Items {
+		if job.Status.Active > 0 {
+			active++
+		}
+	}
+	if active >= globalConcurrentBackupJobsLimit {
+		fmt.Printf("%s %d backup Job(s) owned by CronJob %s are still Active, skipping reconcile\n", debugHeader, active, cronJobName)
+		return nil
+	}
+
+	existing, getErr := client.BatchV1().CronJobs(namespaceName).Get(ctx, cronJobName, metav1.GetOptions{})
+	if getErr != nil {
+		if dryRun {
+			fmt.Printf("%s (dry-run) would create CronJob %s\n", debugHeader, cronJobName)
+			return nil
+		}
+		if debug {
+			fmt.Printf("%s creating CronJob %s\n", debugHeader, cronJobName)
+		}
+		_, err := client.BatchV1().CronJobs(namespaceName).Create(ctx, desired, metav1.CreateOptions{DryRun: dryRunOptions(dryRun)})
+		return err
+	}
+
+	if existing.Spec.Schedule == desired.Spec.Schedule &&
+		fmt.Sprint(existing.Spec.JobTemplate.Spec.Template.Spec.Containers) == fmt.Sprint(desired.Spec.JobTemplate.Spec.Template.Spec.Containers) {
+		if debug {
+			fmt.Printf("%s CronJob %s already up to date\n", debugHeader, cronJobName)
+		}
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("%s (dry-run) CronJob %s would change:\n%s\n", debugHeader, cronJobName, diff.ObjectGoPrintSideBySide(existing.Spec, desired.Spec))
+		return nil
+	}
+
+	updated := existing.DeepCopy()
+	updated.Spec.Schedule = desired.Spec.Schedule
+	updated.Spec.JobTemplate = desired.Spec.JobTemplate
+	_, err := client.BatchV1().CronJobs(namespaceName).Update(ctx, updated, metav1.UpdateOptions{DryRun: dryRunOptions(dryRun)})
+	return err
+}
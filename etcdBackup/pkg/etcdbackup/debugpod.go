@@ -0,0 +1,175 @@
+package etcdbackup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// debugPodName returns a predictable, per-node debug pod name so repeated
+// runs against the same node can find and reuse/clean up the same pod.
+func debugPodName(nodeName string) string {
+	return "etcd-backup-debug-" + nodeName
+}
+
+// createDebugPod creates a privileged Pod pinned to nodeName that chroots
+// into the host filesystem and sleeps, standing in for `oc debug node/<x>`
+// without requiring the oc binary. Commands are then run against it with
+// execInDebugPod.
+func createDebugPod(namespaceName string, nodeName string, imageURL string, serviceAccountName string, taintKey string, taintVal string, debug bool, debugHeader string, client kubernetes.Interface) (*corev1.Pod, error) {
+	priv := true
+	podName := debugPodName(nodeName)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName,
+			Namespace: namespaceName,
+		},
+		Spec: corev1.PodSpec{
+			HostPID:       true,
+			HostNetwork:   true,
+			NodeName:      nodeName,
+			RestartPolicy: corev1.RestartPolicyNever,
+			Tolerations: []corev1.Toleration{
+				{
+					Key:   taintKey,
+					Value: taintVal,
+				},
+			},
+			ServiceAccountName: serviceAccountName,
+			Containers: []corev1.Container{
+				{
+					Name:            "debug",
+					Image:           imageURL,
+					ImagePullPolicy: corev1.PullIfNotPresent,
+					Command:         []string{"/bin/bash", "-c", "chroot /host /bin/bash -c 'sleep infinity' || sleep infinity"},
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: &priv,
+					},
+				},
+			},
+		},
+	}
+
+	if debug {
+		fmt.Printf("%s creating debug pod %s pinned to node %s\n", debugHeader, podName, nodeName)
+	}
+
+	existing, getErr := client.CoreV1().Pods(namespaceName).Get(context.TODO(), podName, metav1.GetOptions{})
+	if getErr == nil {
+		return existing, nil
+	}
+
+	created, err := client.CoreV1().Pods(namespaceName).Create(context.TODO(), pod, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create debug pod %s: %w", podName, err)
+	}
+	return created, nil
+}
+
+// waitForDebugPodRunning polls the debug pod until it reaches the Running
+// phase or the timeout elapses.
+func waitForDebugPodRunning(namespaceName string, podName string, debug bool, debugHeader string, client kubernetes.Interface) error {
+	for i := 0; i < 30; i++ {
+		pod, err := client.CoreV1().Pods(namespaceName).Get(context.TODO(), podName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get debug pod %s: %w", podName, err)
+		}
+		if pod.Status.Phase == corev1.PodRunning {
+			return nil
+		}
+		if debug {
+			fmt.Printf("%s debug pod %s is %s, waited %d seconds\n", debugHeader, podName, pod.Status.Phase, i*2)
+		}
+		time.Sleep(2 * time.Second)
+	}
+	return fmt.Errorf("debug pod %s did not reach Running after 60 seconds", podName)
+}
+
+// execInDebugPod runs command inside the debug pod's single container via
+// the SPDY exec API and returns stdout/stderr, replacing the
+// `oc debug node/<x> -- <cmd>` shell-out.
+func execInDebugPod(restConfig *rest.Config, client kubernetes.Interface, namespaceName string, podName string, command []string) (string, string, error) {
+	req := client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespaceName).
+		SubResource("exec")
+
+	req.VersionedParams(&corev1.PodExecOptions{
+		Container: "debug",
+		Command:   command,
+		Stdout:    true,
+		Stderr:    true,
+	}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(restConfig, "POST", req.URL())
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build SPDY executor for pod %s: %w", podName, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	streamErr := executor.Stream(remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if streamErr != nil {
+		return stdout.String(), stderr.String(), fmt.Errorf("command %v failed in pod %s: %w", command, podName, streamErr)
+	}
+	return stdout.String(), stderr.String(), nil
+}
+
+// streamTarballFromDebugPod cats tarballPath inside the debug pod and writes
+// the raw bytes directly to localPath, streaming over the Kubernetes API
+// exec connection instead of shelling out to `oc debug ... -- cat`.
+func streamTarballFromDebugPod(restConfig *rest.Config, client kubernetes.Interface, namespaceName string, podName string, tarballPath string, localWriter writerCloser) error {
+	req := client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespaceName).
+		SubResource("exec")
+
+	req.VersionedParams(&corev1.PodExecOptions{
+		Container: "debug",
+		Command:   []string{"cat", tarballPath},
+		Stdout:    true,
+		Stderr:    true,
+	}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(restConfig, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to build SPDY executor for pod %s: %w", podName, err)
+	}
+
+	var stderr bytes.Buffer
+	if err := executor.Stream(remotecommand.StreamOptions{
+		Stdout: localWriter,
+		Stderr: &stderr,
+	}); err != nil {
+		return fmt.Errorf("failed to stream %s from pod %s: %s: %w", tarballPath, podName, stderr.String(), err)
+	}
+	return nil
+}
+
+// writerCloser is the minimal interface streamTarballFromDebugPod needs from
+// its destination; *os.File satisfies it.
+type writerCloser interface {
+	Write(p []byte) (int, error)
+}
+
+// deleteDebugPod removes the debug pod once the backup has been pulled.
+func deleteDebugPod(namespaceName string, podName string, client kubernetes.Interface) error {
+	err := client.CoreV1().Pods(namespaceName).Delete(context.TODO(), podName, metav1.DeleteOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to delete debug pod %s: %w", podName, err)
+	}
+	return nil
+}
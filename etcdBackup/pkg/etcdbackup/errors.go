@@ -0,0 +1,11 @@
+package etcdbackup
+
+import "errors"
+
+// ErrJobFailed is returned when the backup Job reaches a Failed status
+// instead of Succeeded.
+var ErrJobFailed = errors.New("backup job failed")
+
+// ErrTimeout is returned when a wait helper gives up before the resource it
+// is watching reaches the desired state.
+var ErrTimeout = errors.New("timed out waiting for resource")
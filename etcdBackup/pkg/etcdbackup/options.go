@@ -0,0 +1,87 @@
+package etcdbackup
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+// Options drives one call to Run. It mirrors the etcd-backup CLI's flags
+// one-to-one so cmd/etcd-backup can remain a thin flag-parsing wrapper; any
+// caller embedding this package (a controller, a test) builds one directly
+// instead of going through flags.
+type Options struct {
+	// RestConfig is required for the CSI snapshot path and the native debug
+	// pod exec/stream path; it cannot be satisfied by a fake clientset.
+	RestConfig         *rest.Config
+	Namespace          string
+	ImageURL           string
+	ServiceAccountName string
+	TaintName          string
+	// Node pins the backup to a specific node. Empty auto-discovers the
+	// first node labeled node-role.kubernetes.io/master=.
+	Node string
+	// JobName names the backup Job. Empty generates "etcd-backup-<random>".
+	JobName string
+
+	UsePVC        bool
+	UseNFS        bool
+	NFSServer     string
+	NFSPath       string
+	NFSVolumeName string
+	NFSClaimName  string
+	// ReclaimPolicy is applied to the static NFS PersistentVolume. Empty
+	// defaults to Retain so deleting the backup namespace never GCs the
+	// volume a backup was written to.
+	ReclaimPolicy     corev1.PersistentVolumeReclaimPolicy
+	UseDynamicStorage bool
+	DynamicClaimName  string
+	PVCSize           string
+
+	SnapshotMode      string
+	SnapshotClassName string
+	RetainSnapshots   int
+
+	// RestoreSnapshotName, if set, switches Run into restore mode: instead of
+	// taking a backup it provisions RestorePVCName from this VolumeSnapshot
+	// and returns, ignoring every other backup-related option below.
+	RestoreSnapshotName     string
+	RestorePVCName          string
+	RestoreVolumeSize       string
+	RestoreStorageClassName string
+
+	BackupTarget       string
+	BackupTargetSecret string
+
+	Schedule   string
+	KeepLast   int
+	KeepWithin time.Duration
+
+	BackupSink           string
+	LocalBackupDirectory string
+	S3                   S3SinkOptions
+	S3RetainObjects      int
+	LegacyOcDebug        bool
+	// OCPBinaryPath is only consulted by the --legacy-oc-debug pull path; the
+	// backup Job itself is pinned directly onto its node via hostPath mounts
+	// and no longer shells out to the oc binary.
+	OCPBinaryPath  string
+	KubeConfigFile string
+
+	DryRun       bool
+	Debug        bool
+	DebugHeader  string
+	PollInterval time.Duration
+}
+
+// Result is the outcome of Run: the parsed `etcdctl snapshot status` for the
+// tarball the backup Job produced, or just the Job/CronJob name when the run
+// had nothing to verify against (no PVC, or a CSI/scheduled run).
+type Result struct {
+	JobName        string
+	Hash           string
+	Revision       int64
+	TotalKeys      int64
+	TotalSizeBytes int64
+}
@@ -0,0 +1,274 @@
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/client-go/kubernetes"
+)
+
+// dryRunOptions returns the Create/Update options to use for a given
+// dryRun setting, so every mutating helper applies dry-run consistently.
+func dryRunOptions(dryRun bool) []string {
+	if dryRun {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+func createClusterBackupRole(namespaceName string, dryRun bool, debug bool, debugHeader string, client kubernetes.Interface) error {
+	// createClusterBackupRole creates or updates a ClusterRole for etcd backup
+	// Args:
+	//     namespaceName: The name of the namespace where the ClusterRole will be created
+	//     dryRun: when true, no changes are persisted; a diff is printed instead
+	//     debug: whether to enable debug logging
+	//     debugHeader: the header to use for debug messages
+	//     client: A Kubernetes clientset used to interact with the Kubernetes API
+	// Define the name and verbs for the ClusterRole
+
+	roleName := "cluster-etcd-backup"
+	nodeVerbs := []string{"get", "list"}
+	apiGroup := []string{""}
+	nodeResources := []string{"nodes"}
+	podVerbs := []string{"get", "list", "create", "delete", "watch"}
+	podResources := []string{"pods", "pods/log"}
+
+	// Define the rules for the ClusterRole
+	rules := []rbac.PolicyRule{
+		{
+			Verbs:     nodeVerbs,
+			APIGroups: apiGroup,
+			Resources: nodeResources,
+		},
+		{
+			Verbs:     podVerbs,
+			APIGroups: apiGroup,
+			Resources: podResources,
+		},
+	}
+
+	clusterRole := &rbac.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: roleName,
+		},
+		Rules: rules,
+	}
+
+	return createOrUpdateClusterRole(clusterRole, dryRun, debug, debugHeader, client)
+}
+
+func createClusterPriviligedRole(namespaceName string, dryRun bool, debug bool, debugHeader string, client kubernetes.Interface) error {
+	// Creates or updates the system:openshift:scc:privileged ClusterRole, which allows use of the privileged security context constraint.
+	// createClusterPrivilegedRole creates a ClusterRole with the given namespace name and client.
+	// The ClusterRole will have the following privileges:
+	// - Use the security context constraint named "privileged" in the "security.openshift.io" API group.
+	// Args:
+	//
+	//	namespaceName: The name of the namespace for which the ClusterRole should be created.
+	//	dryRun: when true, no changes are persisted; a diff is printed instead
+	//	debug: whether to enable debug logging
+	//	debugHeader: the header to use for debug messages
+	//	client: A Kubernetes client used to interact with the Kubernetes API.
+	roleName := "system:openshift:scc:privileged"
+	verbs := []string{"use"}
+	apiGroup := []string{"security.openshift.io"}
+	resources := []string{"securitycontextconstraints"}
+	resourceNames := []string{"privileged"}
+	rules := []rbac.PolicyRule{{Verbs: verbs, APIGroups: apiGroup, Resources: resources, ResourceNames: resourceNames}}
+	clusterRole := &rbac.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: roleName,
+		},
+		Rules: rules,
+	}
+
+	return createOrUpdateClusterRole(clusterRole, dryRun, debug, debugHeader, client)
+}
+
+// createOrUpdateClusterRole fetches the existing ClusterRole and either
+// creates it if missing, or updates it if its rules differ from desired.
+// This fixes the previous bug where createClusterBackupRole/
+// createClusterPriviligedRoleBinding/createClusterBackupRoleBinding called
+// Update unconditionally even when the object did not yet exist.
+func createOrUpdateClusterRole(desired *rbac.ClusterRole, dryRun bool, debug bool, debugHeader string, client kubernetes.Interface) error {
+	existing, getErr := client.RbacV1().ClusterRoles().Get(context.TODO(), desired.Name, metav1.GetOptions{})
+	if getErr != nil {
+		if !apierrors.IsNotFound(getErr) {
+			return fmt.Errorf("failed to get ClusterRole %s: %w", desired.Name, getErr)
+		}
+		if dryRun {
+			fmt.Printf("%s (dry-run) would create ClusterRole %s\n", debugHeader, desired.Name)
+			return nil
+		}
+		if debug {
+			fmt.Printf("%s creating ClusterRole %s\n", debugHeader, desired.Name)
+		}
+		_, err := client.RbacV1().ClusterRoles().Create(context.TODO(), desired, metav1.CreateOptions{DryRun: dryRunOptions(dryRun)})
+		return err
+	}
+
+	if rulesEqual(existing.Rules, desired.Rules) {
+		if debug {
+			fmt.Printf("%s ClusterRole %s already up to date\n", debugHeader, desired.Name)
+		}
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("%s (dry-run) ClusterRole %s would change:\n%s\n", debugHeader, desired.Name, diff.ObjectGoPrintSideBySide(existing.Rules, desired.Rules))
+		return nil
+	}
+
+	updated := existing.DeepCopy()
+	updated.Rules = desired.Rules
+	_, err := client.RbacV1().ClusterRoles().Update(context.TODO(), updated, metav1.UpdateOptions{DryRun: dryRunOptions(dryRun)})
+	return err
+}
+
+// rulesEqual does a simple length+content comparison of two PolicyRule
+// slices, sufficient for detecting drift in the small fixed rule sets this
+// tool manages.
+func rulesEqual(a, b []rbac.PolicyRule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if fmt.Sprint(a[i]) != fmt.Sprint(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func createClusterPriviligedRoleBinding(namespaceName string, serviceAccountName string, dryRun bool, debug bool, debugHeader string, client kubernetes.Interface) error {
+	// Creates or updates the etcd-backup-privileged ClusterRoleBinding, which binds the system:openshift:scc:privileged ClusterRole to the given service account.
+	// createClusterPrivilegedRoleBinding creates a ClusterRoleBinding for the given service account in the specified namespace.
+	// The ClusterRoleBinding grants the service account the "system:openshift:scc:privileged" ClusterRole.
+	//
+	// Args:
+	//       namespaceName: The name of the namespace where the service account is located.
+	//       serviceAccountName: The name of the service account to grant privileges to.
+	//       dryRun: when true, no changes are persisted; a diff is printed instead
+	//       debug: whether to enable debug logging
+	//       debugHeader: the header to use for debug messages
+	//       client: A Kubernetes clientset used to interact with the Kubernetes API.
+	roleName := "etcd-backup-privileged"
+	subjects := []rbac.Subject{{Kind: "ServiceAccount", Name: serviceAccountName, Namespace: namespaceName}}
+	roleRefs := rbac.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "system:openshift:scc:privileged"}
+	clusterRoleBinding := &rbac.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: roleName,
+		},
+		Subjects: subjects,
+		RoleRef:  roleRefs,
+	}
+
+	return createOrUpdateClusterRoleBinding(clusterRoleBinding, dryRun, debug, debugHeader, client)
+}
+
+// Creates or updates the cluster-etcd-backup ClusterRoleBinding, which binds the cluster-etcd-backup ClusterRole to the given service account.
+
+func createClusterBackupRoleBinding(namespaceName string, serviceAccountName string, dryRun bool, debug bool, debugHeader string, client kubernetes.Interface) error {
+	// createClusterBackupRoleBinding creates or updates a ClusterRoleBinding for the given service account in the specified namespace.
+	// The ClusterRoleBinding will give the service account the privileges to perform etcd backups.
+	//
+	// Args:
+	//       namespaceName: The name of the namespace where the service account is located.
+	//       serviceAccountName: The name of the service account to create the ClusterRoleBinding for.
+	//       dryRun: when true, no changes are persisted; a diff is printed instead
+	//       debug: whether to enable debug logging
+	//       debugHeader: the header to use for debug messages
+	//       client: A Kubernetes clientset used to interact with the Kubernetes API.
+	subjects := []rbac.Subject{{Kind: "ServiceAccount", Name: serviceAccountName, Namespace: namespaceName}}
+	roleRefs := rbac.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "cluster-etcd-backup"}
+	clusterRoleBinding := &rbac.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: serviceAccountName,
+		},
+		Subjects: subjects,
+		RoleRef:  roleRefs,
+	}
+
+	return createOrUpdateClusterRoleBinding(clusterRoleBinding, dryRun, debug, debugHeader, client)
+}
+
+// createOrUpdateClusterRoleBinding fetches the existing ClusterRoleBinding
+// and either creates it if missing, or updates it if its subjects/roleRef
+// differ from desired.
+func createOrUpdateClusterRoleBinding(desired *rbac.ClusterRoleBinding, dryRun bool, debug bool, debugHeader string, client kubernetes.Interface) error {
+	existing, getErr := client.RbacV1().ClusterRoleBindings().Get(context.TODO(), desired.Name, metav1.GetOptions{})
+	if getErr != nil {
+		if !apierrors.IsNotFound(getErr) {
+			return fmt.Errorf("failed to get ClusterRoleBinding %s: %w", desired.Name, getErr)
+		}
+		if dryRun {
+			fmt.Printf("%s (dry-run) would create ClusterRoleBinding %s\n", debugHeader, desired.Name)
+			return nil
+		}
+		if debug {
+			fmt.Printf("%s creating ClusterRoleBinding %s\n", debugHeader, desired.Name)
+		}
+		_, err := client.RbacV1().ClusterRoleBindings().Create(context.TODO(), desired, metav1.CreateOptions{DryRun: dryRunOptions(dryRun)})
+		return err
+	}
+
+	if fmt.Sprint(existing.Subjects) == fmt.Sprint(desired.Subjects) && existing.RoleRef == desired.RoleRef {
+		if debug {
+			fmt.Printf("%s ClusterRoleBinding %s already up to date\n", debugHeader, desired.Name)
+		}
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("%s (dry-run) ClusterRoleBinding %s would change:\n%s\n", debugHeader, desired.Name, diff.ObjectGoPrintSideBySide(existing, desired))
+		return nil
+	}
+
+	updated := existing.DeepCopy()
+	updated.Subjects = desired.Subjects
+	updated.RoleRef = desired.RoleRef
+	_, err := client.RbacV1().ClusterRoleBindings().Update(context.TODO(), updated, metav1.UpdateOptions{DryRun: dryRunOptions(dryRun)})
+	return err
+}
+
+func createServiceAccount(namespaceName string, serviceAccountName string, dryRun bool, debug bool, debugHeader string, client kubernetes.Interface) error {
+	// createServiceAccount creates a new ServiceAccount in the specified namespace
+	// with the given name. If the ServiceAccount already exists, it will not be created.
+	// Args:
+	//       namespaceName: The name of the namespace where the service account is located.
+	//       serviceAccountName: The name of the service account to create the ClusterRoleBinding for.
+	//       dryRun: when true, no changes are persisted; a diff is printed instead
+	//       debug: whether to enable debug logging
+	//       debugHeader: the header to use for debug messages
+	//       client: A Kubernetes clientset used to interact with the Kubernetes API.
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceAccountName,
+			Namespace: namespaceName,
+		},
+	}
+
+	_, exist_err := client.CoreV1().ServiceAccounts(namespaceName).Get(context.TODO(), serviceAccountName, metav1.GetOptions{})
+	if exist_err == nil {
+		if debug {
+			fmt.Printf("%s ServiceAccount %s already exists\n", debugHeader, serviceAccountName)
+		}
+		return nil
+	}
+	if !apierrors.IsNotFound(exist_err) {
+		return fmt.Errorf("failed to get ServiceAccount %s: %w", serviceAccountName, exist_err)
+	}
+
+	if dryRun {
+		fmt.Printf("%s (dry-run) would create ServiceAccount %s in namespace %s\n", debugHeader, serviceAccountName, namespaceName)
+		return nil
+	}
+
+	_, err := client.CoreV1().ServiceAccounts(namespaceName).Create(context.TODO(), serviceAccount, metav1.CreateOptions{DryRun: dryRunOptions(dryRun)})
+	return err
+}
@@ -0,0 +1,362 @@
+package etcdbackup
+
+import (
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// hostPathBackupVolumes returns the Volumes/VolumeMounts that pin a backup
+// pod directly onto the target node's etcd data, certs, and
+// cluster-backup.sh via hostPath, plus the node's /tmp so the intermediate
+// tarball lands somewhere pullBackupLocal's debug-pod pull step can still
+// find it. Pairing these with Spec.NodeName is what lets cluster-backup.sh
+// run as a plain container command instead of being wrapped in an
+// `oc debug node/<x> -- chroot /host ...` command string, removing the
+// dependency on the oc binary entirely.
+func hostPathBackupVolumes() ([]corev1.Volume, []corev1.VolumeMount) {
+	hostPathDir := corev1.HostPathDirectory
+	volumes := []corev1.Volume{
+		{Name: "etcd-data", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/lib/etcd", Type: &hostPathDir}}},
+		{Name: "etcd-certs", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/etc/kubernetes/static-pod-resources/etcd-certs", Type: &hostPathDir}}},
+		{Name: "host-bin", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/usr/local/bin", Type: &hostPathDir}}},
+		{Name: "host-tmp", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/tmp", Type: &hostPathDir}}},
+	}
+	mounts := []corev1.VolumeMount{
+		{Name: "etcd-data", MountPath: "/var/lib/etcd", ReadOnly: true},
+		{Name: "etcd-certs", MountPath: "/etc/kubernetes/static-pod-resources/etcd-certs", ReadOnly: true},
+		{Name: "host-bin", MountPath: "/host-bin", ReadOnly: true},
+		{Name: "host-tmp", MountPath: "/host/tmp"},
+	}
+	return volumes, mounts
+}
+
+// splitTaint splits a "key=value" taint spec (or a bare key) into the
+// Toleration's Key/Value, shared by every backup pod builder below.
+func splitTaint(taintName string) (string, string) {
+	taintKey := taintName
+	taintVal := ""
+	if strings.Contains(taintName, "=") {
+		splitVar := strings.Split(taintName, "=")
+		taintKey = splitVar[0]
+		taintVal = splitVar[1]
+	}
+	return taintKey, taintVal
+}
+
+func createBackupPodNoPVC(nodeName string, projectName string, imageURL string, jobName string, serviceAccountName string, taintName string, debug bool, debug_header string) *batchv1.Job {
+	// createBackupPodNoPVC creates a Kubernetes Job that runs cluster-backup.sh
+	// directly on nodeName and tars the result onto the node's own /tmp.
+	// Args:
+	// 		nodeName: the name of the node where the backup should run
+	// 		projectName: the name of the Kubernetes project where the backup should be stored
+	// 		imageURL: the URL of the Docker image to use for the backup container
+	// 		jobName: the name of the Kubernetes Job to create
+	// 		serviceAccountName: the name of the Kubernetes service account to use for the backup
+	// 		taintName: the name of the taint to apply to the node before running the backup
+	// 		debug: whether or not to enable debugging mode
+	// 		debugHeader: the header to use when debugging
+	// The pod is pinned to nodeName and mounts /var/lib/etcd,
+	// /etc/kubernetes/static-pod-resources/etcd-certs, and /usr/local/bin
+	// straight from the host, so cluster-backup.sh runs as a plain container
+	// command instead of being shelled out to via `oc debug`.
+	tempBackupDir := "/tmp/assets/backup"
+	tempTarball := "/host/tmp/etcd_backup.tar.gz"
+	backupCMD := "/host-bin/cluster-backup.sh " + tempBackupDir
+	tarCMD := "tar czf " + tempTarball + " " + tempBackupDir
+	cleanupCMD := "rm -rfv " + tempBackupDir
+
+	priv := true
+	taintKey, taintVal := splitTaint(taintName)
+	volumes, mounts := hostPathBackupVolumes()
+
+	jobSpec := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: projectName,
+		},
+		Spec: batchv1.JobSpec{
+			PodFailurePolicy: &batchv1.PodFailurePolicy{
+				Rules: []batchv1.PodFailurePolicyRule{
+					{
+						OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+							Operator:      batchv1.PodFailurePolicyOnExitCodesOpIn,
+							Values:        []int32{1},
+							ContainerName: &jobName,
+						},
+						Action: batchv1.PodFailurePolicyActionFailJob,
+					},
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					NodeName: nodeName,
+					Tolerations: []corev1.Toleration{
+						{
+							Key:   taintKey,
+							Value: taintVal,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            jobName,
+							Image:           imageURL,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command: []string{
+								"/bin/bash",
+								"-c",
+								backupCMD + " && " + tarCMD + " && " + cleanupCMD,
+							},
+							VolumeMounts: mounts,
+							SecurityContext: &corev1.SecurityContext{
+								Privileged: &priv,
+							},
+						},
+					},
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: serviceAccountName,
+					Volumes:            volumes,
+				},
+			},
+		},
+	}
+
+	return (jobSpec)
+}
+
+func createBackupPodWithPVC(nodeName string, projectName string, imageURL string, firstPVCName string, secondPVCName string, jobName string, serviceAccountName string, taintName string, debug bool, debugHeader string) *batchv1.Job {
+	// createBackupPodWithPVC creates a backup pod with a PVC
+	// Args:
+	// 		nodeName: The name of the node to create the backup pod on
+	// 		projectName: The name of the project to create the backup pod in
+	// 		imageURL: The URL of the image to use for the backup pod
+	// 		firstPVCName: The name of the first PVC to use for the backup pod
+	// 		secondPVCName: The name of the second PVC to use for the backup pod
+	// 		jobName: The name of the job to create
+	// 		serviceAccountName: The name of the service account to use for the backup pod
+	// 		taintName: The name of the taint to add to the backup pod
+	// 		debug: Whether or not to enable debug mode
+	// 		debugHeader: The header to use for debug output
+	// The pod is pinned to nodeName and mounts /var/lib/etcd,
+	// /etc/kubernetes/static-pod-resources/etcd-certs, and /usr/local/bin
+	// straight from the host, so cluster-backup.sh runs as a plain container
+	// command instead of being shelled out to via `oc debug`. Since the PVC
+	// is mounted in the same pod, the tarball is written straight onto it;
+	// no cross-host streaming step is needed.
+	tempBackupDir := "/tmp/assets/backup"
+	tempTarball := "/host/tmp/etcd_backup.tar.gz"
+	backupCMD := "/host-bin/cluster-backup.sh " + tempBackupDir
+	tarCMD := "tar czf " + tempTarball + " " + tempBackupDir
+
+	priv := true
+	taintKey, taintVal := splitTaint(taintName)
+
+	copyFirstTarball := "cat " + tempTarball + " > /backups/backup_$(date +%Y-%m-%d_%H-%M_%Z).db.tgz"
+	cleanupCMD := "rm -rfv " + tempBackupDir + " && rm -f " + tempTarball
+	fullBackupCMD := []string{
+		"/bin/bash",
+		"-c",
+		backupCMD + " && sleep 3 && " + tarCMD + " && sleep 3 && " + copyFirstTarball + " && sleep 3 && " + cleanupCMD,
+	}
+
+	// We need to define the mount and volume before hand so that in the event there are 2 mount points
+	// We can create the definition for the mounts before the pod definition and just pass the mounts in
+	volumeDef, mountDef := hostPathBackupVolumes()
+	mountDef = append(mountDef, corev1.VolumeMount{
+		Name:      "etcd-backup-mount",
+		MountPath: "/backups",
+	})
+	if firstPVCName != "" {
+		if debug {
+			fmt.Printf("%s First PVC Name: %s\n", debugHeader, firstPVCName)
+		}
+		volumeDef = append(volumeDef, corev1.Volume{
+			Name: "etcd-backup-mount",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: firstPVCName,
+				},
+			},
+		})
+	}
+
+	if secondPVCName != "" {
+		if debug {
+			fmt.Printf("%s Second PVC Name: %s\n", debugHeader, secondPVCName)
+		}
+		volumeDef = append(volumeDef, corev1.Volume{
+			Name: "etcd-backup-mount",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: secondPVCName,
+				},
+			},
+		})
+	}
+
+	// if we have both a dynamic and an NFS PVC defined we want to define the pod to have both
+	if firstPVCName != "" && secondPVCName != "" {
+		copySecondTarballCMD := "cat " + tempTarball + " > /backups2/backup_$(date +%Y-%m-%d_%H-%M_%Z).db.tgz"
+		fullBackupCMD = []string{
+			"/bin/bash",
+			"-c",
+			backupCMD + " && sleep 3 && " + tarCMD + " && sleep 3 && " + copySecondTarballCMD + " && sleep 3 && " + cleanupCMD,
+		}
+		hostVolumes, hostMounts := hostPathBackupVolumes()
+		mountDef = append(hostMounts,
+			corev1.VolumeMount{Name: "etcd-backup-mount", MountPath: "/backups"},
+			corev1.VolumeMount{Name: "etcd-backup-mount2", MountPath: "/backups2"},
+		)
+		volumeDef = append(hostVolumes,
+			corev1.Volume{
+				Name: "etcd-backup-mount",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: firstPVCName,
+					},
+				},
+			},
+			corev1.Volume{
+				Name: "etcd-backup-mount2",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: secondPVCName,
+					},
+				},
+			},
+		)
+	}
+
+	jobSpec := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: projectName,
+		},
+		Spec: batchv1.JobSpec{
+			PodFailurePolicy: &batchv1.PodFailurePolicy{
+				Rules: []batchv1.PodFailurePolicyRule{
+					{
+						OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+							Operator:      batchv1.PodFailurePolicyOnExitCodesOpIn,
+							Values:        []int32{1},
+							ContainerName: &jobName,
+						},
+						Action: batchv1.PodFailurePolicyActionFailJob,
+					},
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					NodeName: nodeName,
+					Tolerations: []corev1.Toleration{
+						{
+							Key:   taintKey,
+							Value: taintVal,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            jobName,
+							Image:           imageURL,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         fullBackupCMD,
+							VolumeMounts:    mountDef,
+							SecurityContext: &corev1.SecurityContext{
+								Privileged: &priv,
+							},
+						},
+					},
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: serviceAccountName,
+					Volumes:            volumeDef,
+				},
+			},
+		},
+	}
+
+	return (jobSpec)
+}
+
+// createBackupPodWithTarget creates a Kubernetes Job that runs cluster-backup.sh
+// and tars the result directly on the given node, then hands the tarball off
+// to the given BackupTarget to ship it (PVC, S3, Azure Blob, or a restic
+// repository). This supersedes the hard-coded "cat > /backups/..." step in
+// createBackupPodWithPVC for any backend selected via --backup-target.
+func createBackupPodWithTarget(nodeName string, projectName string, imageURL string, target BackupTarget, jobName string, serviceAccountName string, taintName string, debug bool, debugHeader string) *batchv1.Job {
+	tempBackupDir := "/tmp/assets/backup"
+	tempTarball := "/host/tmp/etcd_backup.tar.gz"
+	backupCMD := "/host-bin/cluster-backup.sh " + tempBackupDir
+	tarCMD := "tar czf " + tempTarball + " " + tempBackupDir
+	cleanupCMD := "rm -rfv " + tempBackupDir + " && rm -f " + tempTarball
+
+	if debug {
+		fmt.Printf("%s Shipping backup via target: %s\n", debugHeader, target.Name())
+	}
+
+	priv := true
+	taintKey, taintVal := splitTaint(taintName)
+
+	fullBackupCMD := []string{
+		"/bin/bash",
+		"-c",
+		backupCMD + " && sleep 3 && " + tarCMD + " && sleep 3 && " + target.ShipCommand(tempTarball) + " && sleep 3 && " + cleanupCMD,
+	}
+
+	volumes, mounts := hostPathBackupVolumes()
+	volumes = append(volumes, target.Volumes()...)
+	mounts = append(mounts, target.VolumeMounts()...)
+
+	jobSpec := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: projectName,
+		},
+		Spec: batchv1.JobSpec{
+			PodFailurePolicy: &batchv1.PodFailurePolicy{
+				Rules: []batchv1.PodFailurePolicyRule{
+					{
+						OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+							Operator:      batchv1.PodFailurePolicyOnExitCodesOpIn,
+							Values:        []int32{1},
+							ContainerName: &jobName,
+						},
+						Action: batchv1.PodFailurePolicyActionFailJob,
+					},
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					NodeName: nodeName,
+					Tolerations: []corev1.Toleration{
+						{
+							Key:   taintKey,
+							Value: taintVal,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            jobName,
+							Image:           imageURL,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         fullBackupCMD,
+							VolumeMounts:    mounts,
+							Env:             target.Env(),
+							EnvFrom:         target.EnvFrom(),
+							SecurityContext: &corev1.SecurityContext{
+								Privileged: &priv,
+							},
+						},
+					},
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: serviceAccountName,
+					Volumes:            volumes,
+				},
+			},
+		},
+	}
+
+	return jobSpec
+}
@@ -0,0 +1,120 @@
+package etcdbackup
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestCreateBackupPodWithPVC_PVCBranching covers the one/two-PVC branching in
+// createBackupPodWithPVC: the backup tarball is copied to one or two
+// PVC-backed mount points depending on which of firstPVCName/secondPVCName
+// are set.
+func TestCreateBackupPodWithPVC_PVCBranching(t *testing.T) {
+	cases := []struct {
+		name             string
+		firstPVCName     string
+		secondPVCName    string
+		wantClaimNames   []string
+		wantMountPaths   []string
+		wantSecondTarget bool
+	}{
+		{
+			name:           "only first PVC",
+			firstPVCName:   "nfs-claim",
+			wantClaimNames: []string{"nfs-claim"},
+			wantMountPaths: []string{"/backups"},
+		},
+		{
+			name:           "only second PVC",
+			secondPVCName:  "dynamic-claim",
+			wantClaimNames: []string{"dynamic-claim"},
+			wantMountPaths: []string{"/backups"},
+		},
+		{
+			name:             "both PVCs",
+			firstPVCName:     "nfs-claim",
+			secondPVCName:    "dynamic-claim",
+			wantClaimNames:   []string{"nfs-claim", "dynamic-claim"},
+			wantMountPaths:   []string{"/backups", "/backups2"},
+			wantSecondTarget: true,
+		},
+		{
+			name:           "neither PVC",
+			wantMountPaths: []string{"/backups"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := createBackupPodWithPVC("node1", "ocp-etcd-backup", "image:latest", tc.firstPVCName, tc.secondPVCName, "etcd-backup-job", "openshift-backup", "node-role.kubernetes.io/master", false, "")
+
+			container := job.Spec.Template.Spec.Containers[0]
+			gotClaimNames := claimNamesFromVolumes(job.Spec.Template.Spec.Volumes)
+			if !sameElements(gotClaimNames, tc.wantClaimNames) {
+				t.Errorf("claim names = %v, want %v", gotClaimNames, tc.wantClaimNames)
+			}
+
+			gotMountPaths := pvcMountPaths(container.VolumeMounts)
+			if !sameElements(gotMountPaths, tc.wantMountPaths) {
+				t.Errorf("PVC mount paths = %v, want %v", gotMountPaths, tc.wantMountPaths)
+			}
+
+			command := container.Command[len(container.Command)-1]
+			hasSecondTarget := containsSubstring(command, "/backups2/")
+			if hasSecondTarget != tc.wantSecondTarget {
+				t.Errorf("command references /backups2/ = %v, want %v; command=%q", hasSecondTarget, tc.wantSecondTarget, command)
+			}
+		})
+	}
+}
+
+func claimNamesFromVolumes(volumes []corev1.Volume) []string {
+	var names []string
+	for _, v := range volumes {
+		if v.PersistentVolumeClaim != nil {
+			names = append(names, v.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	return names
+}
+
+func pvcMountPaths(mounts []corev1.VolumeMount) []string {
+	var paths []string
+	for _, m := range mounts {
+		if m.Name == "etcd-backup-mount" || m.Name == "etcd-backup-mount2" {
+			paths = append(paths, m.MountPath)
+		}
+	}
+	return paths
+}
+
+func containsSubstring(haystack string, needle string) bool {
+	return len(needle) == 0 || (len(haystack) >= len(needle) && indexOf(haystack, needle) >= 0)
+}
+
+func indexOf(haystack string, needle string) int {
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		if haystack[i:i+len(needle)] == needle {
+			return i
+		}
+	}
+	return -1
+}
+
+func sameElements(got []string, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := make(map[string]int)
+	for _, g := range got {
+		seen[g]++
+	}
+	for _, w := range want {
+		if seen[w] == 0 {
+			return false
+		}
+		seen[w]--
+	}
+	return true
+}
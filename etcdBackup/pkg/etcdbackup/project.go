@@ -0,0 +1,70 @@
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// createProject creates a new Kubernetes project with the given name and service account name.
+// It also checks if the project already exists and creates it if it doesn't.
+func createProject(namespaceName string, serviceAccountName string, dryRun bool, debug bool, debugHeader string, client kubernetes.Interface) error {
+	// Check to see if project exists
+	// If project doesn't exist, create it
+	// returns an error if it fails
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namespaceName,
+			Labels: map[string]string{
+				"name": namespaceName,
+			},
+		},
+	}
+	_, existErr := client.CoreV1().Namespaces().Get(context.TODO(), namespaceName, metav1.GetOptions{})
+
+	if existErr != nil {
+		if !apierrors.IsNotFound(existErr) {
+			return fmt.Errorf("failed to get namespace %s: %w", namespaceName, existErr)
+		}
+		if debug {
+			fmt.Printf("%s project: %s did not exist\n", debugHeader, namespaceName)
+			fmt.Printf("%s creating the project %s\n", debugHeader, namespaceName)
+		}
+		if dryRun {
+			fmt.Printf("%s (dry-run) would create namespace %s\n", debugHeader, namespaceName)
+		} else {
+			_, createNamespaceError := client.CoreV1().Namespaces().Create(context.TODO(), namespace, metav1.CreateOptions{DryRun: dryRunOptions(dryRun)})
+			if createNamespaceError != nil {
+				fmt.Println("Failed to create namespace")
+				return createNamespaceError
+			}
+		}
+	}
+
+	fmt.Println("Creating service account...")
+	if err := createServiceAccount(namespaceName, serviceAccountName, dryRun, debug, debugHeader, client); err != nil {
+		return fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	fmt.Println("Ensuring that ClusterRole exists...")
+	if err := createClusterPriviligedRole(namespaceName, dryRun, debug, debugHeader, client); err != nil {
+		return fmt.Errorf("failed to reconcile privileged ClusterRole: %w", err)
+	}
+	if err := createClusterBackupRole(namespaceName, dryRun, debug, debugHeader, client); err != nil {
+		return fmt.Errorf("failed to reconcile backup ClusterRole: %w", err)
+	}
+
+	fmt.Println("Checking to make sure ClusterRole is applied to " + serviceAccountName + " service account...")
+	if err := createClusterPriviligedRoleBinding(namespaceName, serviceAccountName, dryRun, debug, debugHeader, client); err != nil {
+		return fmt.Errorf("failed to reconcile privileged ClusterRoleBinding: %w", err)
+	}
+	if err := createClusterBackupRoleBinding(namespaceName, serviceAccountName, dryRun, debug, debugHeader, client); err != nil {
+		return fmt.Errorf("failed to reconcile backup ClusterRoleBinding: %w", err)
+	}
+
+	return nil
+}
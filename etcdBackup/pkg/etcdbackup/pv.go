@@ -0,0 +1,118 @@
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// createPersistentNFSVolume reconciles a static NFS-backed PersistentVolume.
+// Args:
+// 		namespaceName: the name of the Kubernetes namespace where the PV will be created
+// 		nfsServer: the hostname or IP address of the NFS server
+// 		nfsPath: the path on the NFS server where the data will be stored
+// 		debug: a boolean indicating whether debug output should be printed
+// 		debugHeader: a string that will be prepended to each debug message
+// 		volumeName: the name of the PV
+// 		claimName: the name of the PersistentVolumeClaim (PVC) that will be used to bind the PV
+// 		reclaimPolicy: the PV's reclaim policy; defaults to Retain so deleting the backup namespace never GCs the volume
+// 		dryRun: when true, no changes are persisted; a diff is printed instead
+// 		client: a kubernetes.Interface used to interact with the Kubernetes API
+// This assumes the creation of an NFS volume. It will create the PV with a
+// ClaimRef so that no other PVCs will bind to it.
+//
+// Like a CSI driver's static PV, the NFS server/path and ClaimRef.UID here
+// identify which backing volume and claim the PV belongs to. If a PV already
+// exists, this refuses to mutate it unless that identity is safe to change:
+// a source mismatch is always an error, and a ClaimRef is only cleared once
+// the PVC it points to is confirmed gone.
+func createPersistentNFSVolume(namespaceName string, nfsServer string, nfsPath string, debug bool, debugHeader string, volumeName string, claimName string, reclaimPolicy corev1.PersistentVolumeReclaimPolicy, dryRun bool, client kubernetes.Interface) error {
+	accessMode := []corev1.PersistentVolumeAccessMode{"ReadWriteMany"}
+	volumeSize := "10Gi"
+	if reclaimPolicy == "" {
+		reclaimPolicy = corev1.PersistentVolumeReclaimRetain
+	}
+
+	// Create the PV spec
+	volumeSpec := &corev1.PersistentVolume{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "PersistentVolume",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: volumeName,
+		},
+
+		Spec: corev1.PersistentVolumeSpec{
+
+			AccessModes: accessMode,
+			Capacity: corev1.ResourceList{
+				corev1.ResourceName(corev1.ResourceStorage): resource.MustParse(volumeSize),
+			},
+			PersistentVolumeReclaimPolicy: reclaimPolicy,
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				NFS: &corev1.NFSVolumeSource{
+					Path:   nfsPath,
+					Server: nfsServer,
+				},
+			},
+			ClaimRef: &corev1.ObjectReference{
+				Name:      claimName,
+				Namespace: namespaceName,
+			},
+		},
+	}
+
+	// Get the existing PV, if any
+	existing, getPVError := client.CoreV1().PersistentVolumes().Get(context.TODO(), volumeName, metav1.GetOptions{})
+
+	if getPVError != nil {
+		if !apierrors.IsNotFound(getPVError) {
+			return fmt.Errorf("failed to get Persistent Volume %s: %w", volumeName, getPVError)
+		}
+		if debug {
+			fmt.Printf("%s %s\n", debugHeader, getPVError)
+		}
+		fmt.Println("No existing Persistent Volume found, creating a new one...")
+		if _, createPVError := client.CoreV1().PersistentVolumes().Create(context.TODO(), volumeSpec, metav1.CreateOptions{DryRun: dryRunOptions(dryRun)}); createPVError != nil {
+			return fmt.Errorf("failed to create Persistent Volume %s: %w", volumeName, createPVError)
+		}
+		return nil
+	}
+	// We want to only update the PersistentVolume if the Claim is unbound or in another state
+	// If the claim is already bound, don't touch the PV
+	claimOutput, getClaimError := client.CoreV1().PersistentVolumeClaims(namespaceName).Get(context.TODO(), claimName, metav1.GetOptions{})
+	if getClaimError == nil && claimOutput.Status.Phase == "Bound" {
+		fmt.Printf("%s PVC is already bound to the PV... No action taken\n", debugHeader)
+		return nil
+	}
+
+	if existing.Spec.NFS == nil || existing.Spec.NFS.Server != nfsServer || existing.Spec.NFS.Path != nfsPath {
+		return fmt.Errorf("PV %s already exists with a different NFS source (%+v), refusing to repoint it at server=%s path=%s", volumeName, existing.Spec.NFS, nfsServer, nfsPath)
+	}
+
+	if existing.Spec.ClaimRef != nil && existing.Spec.ClaimRef.UID != "" {
+		if _, err := client.CoreV1().PersistentVolumeClaims(existing.Spec.ClaimRef.Namespace).Get(context.TODO(), existing.Spec.ClaimRef.Name, metav1.GetOptions{}); err == nil {
+			return fmt.Errorf("PV %s ClaimRef still points at existing PVC %s/%s, refusing to strip it", volumeName, existing.Spec.ClaimRef.Namespace, existing.Spec.ClaimRef.Name)
+		}
+	}
+
+	// Because OCP adds resource versions and uuid, if the PVC gets deleted for some reason, the PV will never become bound
+	// Therefore we want to update the PV definition to remove UUID and resource version information,
+	// now that we've confirmed the PVC it was bound to is truly gone
+	updated := existing.DeepCopy()
+	updated.ResourceVersion = ""
+	updated.UID = ""
+	updated.Spec.ClaimRef = volumeSpec.Spec.ClaimRef
+	updated.Spec.PersistentVolumeReclaimPolicy = reclaimPolicy
+	if _, updatePVError := client.CoreV1().PersistentVolumes().Update(context.TODO(), updated, metav1.UpdateOptions{DryRun: dryRunOptions(dryRun)}); updatePVError != nil {
+		return fmt.Errorf("failed to update Persistent Volume %s: %w", volumeName, updatePVError)
+	}
+	fmt.Printf("%s the PV has been updated with the new PVC\n", debugHeader)
+	return nil
+}
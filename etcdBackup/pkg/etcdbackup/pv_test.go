@@ -0,0 +1,106 @@
+package etcdbackup
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	testNamespace  = "ocp-etcd-backup"
+	testVolumeName = "etcd-nfs-backup-vol"
+	testClaimName  = "etcd-nfs-backup-claim"
+	testNFSServer  = "nfs.example.com"
+	testNFSPath    = "/exports/etcd-backup"
+)
+
+func nfsPV(server string, claimRefUID string) *corev1.PersistentVolume {
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: testVolumeName},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				NFS: &corev1.NFSVolumeSource{Server: server, Path: testNFSPath},
+			},
+		},
+	}
+	if claimRefUID != "" {
+		pv.Spec.ClaimRef = &corev1.ObjectReference{
+			Name:      testClaimName,
+			Namespace: testNamespace,
+			UID:       types.UID("claim-ref-" + claimRefUID),
+		}
+	}
+	return pv
+}
+
+func pvc(phase corev1.PersistentVolumeClaimPhase) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: testClaimName, Namespace: testNamespace, UID: "claim-ref-live"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+	}
+}
+
+// TestCreatePersistentNFSVolume_BoundVsUnbound covers the branch in
+// createPersistentNFSVolume that decides whether an existing PV may be
+// reconciled: no PV yet (create), PVC already bound (no-op), PVC unbound
+// with a live ClaimRef (refuse), NFS source mismatch (refuse), and PVC
+// unbound with no live ClaimRef (update).
+func TestCreatePersistentNFSVolume_BoundVsUnbound(t *testing.T) {
+	cases := []struct {
+		name    string
+		objects []runtime.Object
+		wantErr bool
+	}{
+		{
+			name: "no existing PV creates one",
+		},
+		{
+			name:    "PVC already bound is a no-op",
+			objects: []runtime.Object{nfsPV(testNFSServer, "live"), pvc(corev1.ClaimBound)},
+		},
+		{
+			name:    "NFS source mismatch is refused",
+			objects: []runtime.Object{nfsPV("other-server", ""), pvc(corev1.ClaimPending)},
+			wantErr: true,
+		},
+		{
+			name:    "live ClaimRef still bound is refused",
+			objects: []runtime.Object{nfsPV(testNFSServer, "live"), pvc(corev1.ClaimPending)},
+			wantErr: true,
+		},
+		{
+			name:    "unbound PVC with no live ClaimRef is updated",
+			objects: []runtime.Object{nfsPV(testNFSServer, "")},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tc.objects...)
+
+			err := createPersistentNFSVolume(testNamespace, testNFSServer, testNFSPath, false, "", testVolumeName, testClaimName, corev1.PersistentVolumeReclaimRetain, false, client)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			pv, getErr := client.CoreV1().PersistentVolumes().Get(context.TODO(), testVolumeName, metav1.GetOptions{})
+			if getErr != nil {
+				t.Fatalf("expected PV %s to exist after reconcile: %v", testVolumeName, getErr)
+			}
+			if pv.Spec.NFS == nil || pv.Spec.NFS.Server != testNFSServer {
+				t.Errorf("PV NFS source = %+v, want server %s", pv.Spec.NFS, testNFSServer)
+			}
+		})
+	}
+}
@@ -1,15 +1,15 @@
-package main
+package etcdbackup
 
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -81,39 +81,37 @@ func createPVCDefinition(namespaceName string, pvcName string, volumeName string
 	return accessMode, pvcSpec
 }
 
-func createMissingPVCs(namespaceName string, nfsPVCName string, volumeName string, volumeSize string, debug bool, debugHeader string, client *kubernetes.Clientset) {
+func createMissingPVCs(ctx context.Context, namespaceName string, nfsPVCName string, volumeName string, volumeSize string, pollInterval time.Duration, dryRun bool, debug bool, debugHeader string, client kubernetes.Interface) error {
 	// createMissingPVCs creates a PVC if it doesn't exist already and checks to make sure it isn't lost.
 	// If the PVC is lost, it will be deleted and recreated.
 	// Args:
+	//     ctx: context controlling cancellation and the overall wait timeout
 	//     namespaceName: the name of the Kubernetes namespace where the PVC should be created
 	//     nfsPVCName: the name of the NFS PVC to create or retrieve
 	//     volumeName: the name of the volume to use for the PVC
 	//     volumeSize: the size of the volume to use for the PVC
+	//     pollInterval: the base interval used by the exponential-backoff waits below
+	//     dryRun: when true, no changes are persisted; a diff is printed instead
 	//     debug: whether or not to enable debug logging
 	//     debugHeader: a header to use when printing debug messages
 	//     client: a Kubernetes clientset to use for interacting with the cluster
+	// Returns nil on success, or ErrTimeout/ctx.Err() if the PVC never settles.
 
 	createPVC := false
 	accessMode := []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
 
 	// Check if the PVC already exists
-	claimOutput, existErr := client.CoreV1().PersistentVolumeClaims(namespaceName).Get(context.TODO(), nfsPVCName, metav1.GetOptions{})
+	claimOutput, existErr := client.CoreV1().PersistentVolumeClaims(namespaceName).Get(ctx, nfsPVCName, metav1.GetOptions{})
 	if claimOutput.Status.Phase == "Lost" {
 		// If the PVC is lost, delete it and recreate it
-		deletePVCError := client.CoreV1().PersistentVolumeClaims(namespaceName).Delete(context.TODO(), nfsPVCName, metav1.DeleteOptions{})
-		i := 0
-		// We want to wait up to 30 seconds for a terminating PVC to be removed
-		for i <= 3 {
-			claimOutput, _ := client.CoreV1().PersistentVolumeClaims(namespaceName).Get(context.TODO(), nfsPVCName, metav1.GetOptions{})
-			if claimOutput.Status.Phase != "Terminating" {
-				time.Sleep(10 * time.Second)
-			}
-
-			i++
-		}
+		deletePVCError := client.CoreV1().PersistentVolumeClaims(namespaceName).Delete(ctx, nfsPVCName, metav1.DeleteOptions{})
 		if deletePVCError != nil {
 			fmt.Println("The PVC was in a 'Lost' state but it could not be removed. Please investigate")
-			panic(deletePVCError)
+			return deletePVCError
+		}
+		// We want to wait for the terminating PVC to be removed before recreating it
+		if err := waitForPVCPhaseNot(ctx, namespaceName, nfsPVCName, "Terminating", pollInterval, debug, debugHeader, client); err != nil {
+			return err
 		}
 	}
 
@@ -127,43 +125,78 @@ func createMissingPVCs(namespaceName string, nfsPVCName string, volumeName strin
 				fmt.Printf("%s Current Access Mode: %s\n                        Requested Access Mode: %s\n", debugHeader, claimOutput.Status.AccessModes[0], accessMode[0])
 				fmt.Printf("%s Volume Name: %s\n", debugHeader, claimOutput.Spec.VolumeName)
 			}
-			fmt.Println("PVC already exists")
-			fmt.Println("Access Mode of existing PVC does not match")
-			fmt.Println("Exiting")
-			os.Exit(1)
-
+			return fmt.Errorf("PVC %s already exists with access mode %s, requested %s", nfsPVCName, claimOutput.Status.AccessModes[0], accessMode[0])
 		}
 	}
 	// a "GET" error is not necessarily bad at first, it could mean this is the first time the job is run
 	// Create the PVC if it doesn't exist
 	if existErr != nil || createPVC == true {
+		if dryRun {
+			fmt.Printf("%s (dry-run) would create PVC %s\n", debugHeader, nfsPVCName)
+			return nil
+		}
 		if debug {
 			fmt.Printf("%s Attempting to create the PVC: %s\n", debugHeader, nfsPVCName)
 		}
-		_, createPVCError := client.CoreV1().PersistentVolumeClaims(namespaceName).Create(context.TODO(), pvcSpec, metav1.CreateOptions{})
+		_, createPVCError := client.CoreV1().PersistentVolumeClaims(namespaceName).Create(ctx, pvcSpec, metav1.CreateOptions{DryRun: dryRunOptions(dryRun)})
 
 		if createPVCError != nil {
 			fmt.Println("Failed to create PVC")
-			panic(createPVCError)
+			return createPVCError
 		}
-		i := 0
-		// Wait for up to 100 seconds for the PVC to become bound
-		for i <= 10 {
-			claimOutput, _ := client.CoreV1().PersistentVolumeClaims(namespaceName).Get(context.TODO(), nfsPVCName, metav1.GetOptions{})
-			if claimOutput.Status.Phase != "Bound" {
-				time.Sleep(10 * time.Second)
-				timeElapsed := i * 10
-				fmt.Printf("PVC is not yet bound after %d\n", timeElapsed)
-			}
-			if claimOutput.Status.Phase == "Bound" {
-				return
-			}
-			i++
-			// If we cannot bind to a PV, halt the program
-			if i == 10 {
-				panic("Problem binding PVC to a PV... exiting")
-			}
+		return waitForPVCBound(ctx, namespaceName, nfsPVCName, pollInterval, debug, debugHeader, client)
+	}
+
+	return nil
+}
+
+// waitForPVCBound polls the PVC with an exponential backoff until it reaches
+// the Bound phase, replacing the previous fixed 10x10s sleep loop.
+func waitForPVCBound(ctx context.Context, namespaceName string, pvcName string, pollInterval time.Duration, debug bool, debugHeader string, client kubernetes.Interface) error {
+	backoff := wait.Backoff{
+		Duration: pollInterval,
+		Factor:   1.5,
+		Jitter:   0.1,
+		Steps:    10,
+	}
+	err := wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		claimOutput, getErr := client.CoreV1().PersistentVolumeClaims(namespaceName).Get(ctx, pvcName, metav1.GetOptions{})
+		if getErr != nil {
+			return false, getErr
 		}
+		if debug {
+			fmt.Printf("%s PVC %s is in phase %s\n", debugHeader, pvcName, claimOutput.Status.Phase)
+		}
+		return claimOutput.Status.Phase == corev1.ClaimBound, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return ErrTimeout
 	}
+	return err
+}
 
+// waitForPVCPhaseNot polls the PVC with an exponential backoff until it is no
+// longer in the given phase (e.g. waiting for "Terminating" to clear).
+func waitForPVCPhaseNot(ctx context.Context, namespaceName string, pvcName string, phase corev1.PersistentVolumeClaimPhase, pollInterval time.Duration, debug bool, debugHeader string, client kubernetes.Interface) error {
+	backoff := wait.Backoff{
+		Duration: pollInterval,
+		Factor:   1.5,
+		Jitter:   0.1,
+		Steps:    6,
+	}
+	err := wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		claimOutput, getErr := client.CoreV1().PersistentVolumeClaims(namespaceName).Get(ctx, pvcName, metav1.GetOptions{})
+		if getErr != nil {
+			// Not found means the PVC finished terminating.
+			return true, nil
+		}
+		if debug {
+			fmt.Printf("%s PVC %s is in phase %s\n", debugHeader, pvcName, claimOutput.Status.Phase)
+		}
+		return claimOutput.Status.Phase != phase, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return ErrTimeout
+	}
+	return err
 }
@@ -0,0 +1,259 @@
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Run performs one etcd backup end to end: it reconciles the backup
+// project/RBAC, picks a control-plane node if opts.Node is empty, then
+// dispatches to the CSI snapshot, --backup-target, or legacy PVC/no-PVC path
+// depending on what opts asks for.
+//
+// This is the library entrypoint cmd/etcd-backup's main() calls after
+// parsing flags into Options; every error that used to panic or os.Exit
+// inside main is now returned instead, so Run can be embedded in a
+// controller or exercised with a fake clientset in tests.
+func Run(ctx context.Context, cs kubernetes.Interface, opts Options) (*Result, error) {
+	if opts.ServiceAccountName == "" {
+		opts.ServiceAccountName = "openshift-backup"
+	}
+	if opts.PVCSize == "" {
+		opts.PVCSize = "5Gi"
+	}
+	if opts.DebugHeader == "" {
+		opts.DebugHeader = "    (DEBUG)    --->    "
+	}
+	jobName := opts.JobName
+	if jobName == "" {
+		jobName = "etcd-backup-" + randomString(4)
+	}
+
+	if opts.RestoreSnapshotName != "" {
+		return runRestore(ctx, cs, opts)
+	}
+
+	node := opts.Node
+	if node == "" {
+		fmt.Println("Attempting to find nodes with the label: node-role.kubernetes.io/master=")
+		nodes, err := cs.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: "node-role.kubernetes.io/master="})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list control-plane nodes: %w", err)
+		}
+		if len(nodes.Items) == 0 {
+			return nil, fmt.Errorf("no nodes found with label node-role.kubernetes.io/master=")
+		}
+		node = nodes.Items[0].Name
+	}
+	if opts.Debug {
+		fmt.Printf("%s using node: %s\n", opts.DebugHeader, node)
+	}
+
+	if opts.Debug {
+		fmt.Printf("%s attempting to use project: %s\n", opts.DebugHeader, opts.Namespace)
+		fmt.Printf("%s Project will be created if it doesn't exist\n", opts.DebugHeader)
+	}
+	if err := createProject(opts.Namespace, opts.ServiceAccountName, opts.DryRun, opts.Debug, opts.DebugHeader, cs); err != nil {
+		return nil, fmt.Errorf("failed to reconcile backup project: %w", err)
+	}
+
+	if opts.SnapshotMode == "csi" {
+		return runCSIBackup(opts, node)
+	}
+
+	if opts.BackupTarget != "" {
+		return runTargetBackup(ctx, cs, opts, node, jobName)
+	}
+
+	return runLegacyBackup(ctx, cs, opts, node, jobName)
+}
+
+// runRestore drives --restore-from-snapshot: instead of taking a backup, it
+// provisions opts.RestorePVCName from the given CSI VolumeSnapshot so a
+// point-in-time backup can be mounted back onto a pod for recovery.
+func runRestore(ctx context.Context, cs kubernetes.Interface, opts Options) (*Result, error) {
+	if opts.RestorePVCName == "" {
+		return nil, fmt.Errorf("--restore-pvc-name is required when --restore-from-snapshot is set")
+	}
+	if opts.RestoreVolumeSize == "" {
+		opts.RestoreVolumeSize = "10Gi"
+	}
+
+	pvc, err := restoreFromSnapshot(opts.Namespace, opts.RestorePVCName, opts.RestoreSnapshotName, opts.RestoreVolumeSize, opts.RestoreStorageClassName, opts.Debug, opts.DebugHeader, cs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore PVC from snapshot: %w", err)
+	}
+	fmt.Println("Restored PVC " + pvc.Name + " from VolumeSnapshot " + opts.RestoreSnapshotName)
+	return &Result{JobName: pvc.Name}, nil
+}
+
+// runCSIBackup drives --snapshot-mode=csi, which never produces a Job for
+// runBackupJob to verify: success is the VolumeSnapshot becoming ready.
+func runCSIBackup(opts Options, node string) (*Result, error) {
+	sourcePVC := opts.NFSClaimName
+	if sourcePVC == "" {
+		sourcePVC = opts.DynamicClaimName
+	}
+	if sourcePVC == "" {
+		return nil, fmt.Errorf("--snapshot-mode=csi requires --nfs-claim-name or --dynamic-claim-name to identify the PVC to snapshot")
+	}
+	if opts.SnapshotClassName == "" {
+		return nil, fmt.Errorf("--snapshot-class is required when --snapshot-mode=csi")
+	}
+
+	if err := runCSISnapshotBackup(opts.RestConfig, opts.Namespace, sourcePVC, opts.SnapshotClassName, opts.RetainSnapshots, opts.Debug, opts.DebugHeader); err != nil {
+		return nil, fmt.Errorf("CSI snapshot backup failed: %w", err)
+	}
+	return &Result{}, nil
+}
+
+// runTargetBackup drives --backup-target, shipping the tarball via the
+// requested BackupTarget instead of the legacy PVC-only path. With
+// --schedule set this reconciles a CronJob instead of running a one-shot Job.
+func runTargetBackup(ctx context.Context, cs kubernetes.Interface, opts Options, node string, jobName string) (*Result, error) {
+	target, err := NewBackupTarget(opts.BackupTarget, opts.BackupTargetSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Schedule != "" {
+		cronJobName := "etcd-backup-schedule"
+		scheduledJobTemplate := createBackupPodWithTarget(node, opts.Namespace, opts.ImageURL, target, "etcd-backup", opts.ServiceAccountName, opts.TaintName, opts.Debug, opts.DebugHeader)
+		cronJob := buildBackupCronJob(cronJobName, opts.Namespace, scheduledJobTemplate, opts.Schedule, "/backups", opts.KeepLast, opts.KeepWithin)
+		if err := reconcileScheduledBackup(ctx, opts.Namespace, cronJobName, cronJob, opts.DryRun, opts.Debug, opts.DebugHeader, cs); err != nil {
+			return nil, fmt.Errorf("failed to reconcile backup CronJob: %w", err)
+		}
+		return &Result{JobName: cronJobName}, nil
+	}
+
+	backupJob := createBackupPodWithTarget(node, opts.Namespace, opts.ImageURL, target, jobName, opts.ServiceAccountName, opts.TaintName, opts.Debug, opts.DebugHeader)
+	return runBackupJob(ctx, backupJobOptions{
+		Client:             cs,
+		Namespace:          opts.Namespace,
+		Node:               node,
+		ImageURL:           opts.ImageURL,
+		ServiceAccountName: opts.ServiceAccountName,
+		JobName:            jobName,
+		BackupJob:          backupJob,
+		PollInterval:       opts.PollInterval,
+		Debug:              opts.Debug,
+		DebugHeader:        opts.DebugHeader,
+	})
+}
+
+// runLegacyBackup drives the original oc-debug tarball path: optionally
+// provisioning a PVC (or a recurring CronJob, with --schedule), verifying the
+// tarball once the backup Job completes, and pulling it to local disk (or a
+// BackupSink) when no PVC is in play.
+func runLegacyBackup(ctx context.Context, cs kubernetes.Interface, opts Options, node string, jobName string) (*Result, error) {
+	backupJob := createBackupPodNoPVC(node, opts.Namespace, opts.ImageURL, jobName, opts.ServiceAccountName, opts.TaintName, opts.Debug, opts.DebugHeader)
+
+	if opts.UsePVC {
+		if opts.UseNFS {
+			fmt.Println("Checking to see if we need to create PV")
+			if err := createPersistentNFSVolume(opts.Namespace, opts.NFSServer, opts.NFSPath, opts.Debug, opts.DebugHeader, opts.NFSVolumeName, opts.NFSClaimName, opts.ReclaimPolicy, opts.DryRun, cs); err != nil {
+				return nil, fmt.Errorf("failed to reconcile NFS PersistentVolume: %w", err)
+			}
+		}
+
+		fmt.Println("Checking to see if we need to create PVC")
+		if opts.UseNFS {
+			if opts.Debug {
+				fmt.Printf("%s Creating NFS PVC\n", opts.DebugHeader)
+			}
+			if err := createMissingPVCs(ctx, opts.Namespace, opts.NFSClaimName, opts.NFSVolumeName, opts.PVCSize, opts.PollInterval, opts.DryRun, opts.Debug, opts.DebugHeader, cs); err != nil {
+				return nil, fmt.Errorf("failed to create/bind NFS PVC: %w", err)
+			}
+		}
+		if opts.UseDynamicStorage {
+			if opts.Debug {
+				fmt.Printf("%s Creating Dynamic Storage PVC\n", opts.DebugHeader)
+			}
+			if err := createMissingPVCs(ctx, opts.Namespace, opts.DynamicClaimName, "", opts.PVCSize, opts.PollInterval, opts.DryRun, opts.Debug, opts.DebugHeader, cs); err != nil {
+				return nil, fmt.Errorf("failed to create/bind dynamic storage PVC: %w", err)
+			}
+		}
+
+		if opts.Schedule != "" {
+			cronJobName := "etcd-backup-schedule"
+			backupDir := "/backups"
+			scheduledJobTemplate := createBackupPodWithPVC(node, opts.Namespace, opts.ImageURL, opts.NFSClaimName, opts.DynamicClaimName, "etcd-backup", opts.ServiceAccountName, opts.TaintName, opts.Debug, opts.DebugHeader)
+			cronJob := buildBackupCronJob(cronJobName, opts.Namespace, scheduledJobTemplate, opts.Schedule, backupDir, opts.KeepLast, opts.KeepWithin)
+			if err := reconcileScheduledBackup(ctx, opts.Namespace, cronJobName, cronJob, opts.DryRun, opts.Debug, opts.DebugHeader, cs); err != nil {
+				return nil, fmt.Errorf("failed to reconcile backup CronJob: %w", err)
+			}
+			return &Result{JobName: cronJobName}, nil
+		}
+
+		if opts.Debug && opts.UseNFS {
+			fmt.Printf("%s Job: %s\n 			Project: %s \n 			Node: %s\n			PVC: %s\n", opts.DebugHeader, jobName, opts.Namespace, node, opts.NFSClaimName)
+		}
+		backupJob = createBackupPodWithPVC(node, opts.Namespace, opts.ImageURL, opts.NFSClaimName, opts.DynamicClaimName, jobName, opts.ServiceAccountName, opts.TaintName, opts.Debug, opts.DebugHeader)
+	} else if opts.Schedule != "" {
+		return nil, fmt.Errorf("--schedule requires --use-pvc so the retention policy has a persistent place to list/delete tarballs from")
+	}
+
+	verifyPVCName := ""
+	if opts.UsePVC {
+		if opts.NFSClaimName != "" {
+			verifyPVCName = opts.NFSClaimName
+		} else if opts.DynamicClaimName != "" {
+			verifyPVCName = opts.DynamicClaimName
+		}
+	}
+
+	result, err := runBackupJob(ctx, backupJobOptions{
+		Client:             cs,
+		Namespace:          opts.Namespace,
+		Node:               node,
+		ImageURL:           opts.ImageURL,
+		ServiceAccountName: opts.ServiceAccountName,
+		JobName:            jobName,
+		BackupJob:          backupJob,
+		PVCName:            verifyPVCName,
+		PollInterval:       opts.PollInterval,
+		Debug:              opts.Debug,
+		DebugHeader:        opts.DebugHeader,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.UsePVC {
+		fmt.Println("Starting to pull backup locally")
+		sink, err := buildBackupSink(opts.BackupSink, S3SinkOptions{
+			Bucket:      opts.S3.Bucket,
+			EndpointURL: opts.S3.EndpointURL,
+			Region:      opts.S3.Region,
+			KeyPrefix:   opts.S3.KeyPrefix,
+			SSECKey:     opts.S3.SSECKey,
+			Debug:       opts.Debug,
+			DebugHeader: opts.DebugHeader,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build backup sink: %w", err)
+		}
+		if err := pullBackupLocal(node, opts.LocalBackupDirectory, opts.Namespace, jobName, opts.Debug, opts.DebugHeader, opts.KubeConfigFile, opts.OCPBinaryPath, cs, sink, opts.S3RetainObjects, opts.RestConfig, opts.LegacyOcDebug, opts.ImageURL, opts.TaintName, opts.ServiceAccountName); err != nil {
+			return nil, fmt.Errorf("failed to pull backup locally: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// buildBackupSink constructs the BackupSink implementation requested via
+// --backup-sink. Unknown sink names fall back to LocalSink.
+func buildBackupSink(sinkName string, s3Opts S3SinkOptions) (BackupSink, error) {
+	switch sinkName {
+	case "s3":
+		if s3Opts.Bucket == "" {
+			return nil, fmt.Errorf("--s3-bucket is required when --backup-sink=s3")
+		}
+		return NewS3Sink(context.TODO(), s3Opts)
+	default:
+		return LocalSink{}, nil
+	}
+}
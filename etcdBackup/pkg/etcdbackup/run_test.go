@@ -0,0 +1,28 @@
+package etcdbackup
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestRun_RestoreModeSkipsNodeDiscovery covers the bug where Run looked for
+// a node labeled node-role.kubernetes.io/master= before checking
+// opts.RestoreSnapshotName, so --restore-from-snapshot failed on any
+// clientset with no such node even though runRestore never uses one.
+func TestRun_RestoreModeSkipsNodeDiscovery(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	result, err := Run(context.TODO(), client, Options{
+		Namespace:           "ocp-etcd-backup",
+		RestoreSnapshotName: "etcd-snapshot-1",
+		RestorePVCName:      "etcd-restore-claim",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || result.JobName != "etcd-restore-claim" {
+		t.Errorf("result = %+v, want JobName %q", result, "etcd-restore-claim")
+	}
+}
@@ -0,0 +1,139 @@
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// BackupSink is where a completed local tarball ends up after
+// pullBackupLocal writes it to disk. The default LocalSink leaves the file
+// where it is; other sinks ship it off the jump host to object storage.
+type BackupSink interface {
+	Upload(ctx context.Context, localPath string, key string) error
+}
+
+// LocalSink is a no-op sink representing the existing "leave it on disk"
+// behavior of pullBackupLocal.
+type LocalSink struct{}
+
+// Upload satisfies BackupSink for LocalSink; there is nothing to do since
+// the tarball already lives at localPath.
+func (LocalSink) Upload(ctx context.Context, localPath string, key string) error {
+	return nil
+}
+
+// S3SinkOptions configures an S3Sink. It also covers S3-API-compatible
+// object stores (MinIO, Ceph RGW) via EndpointURL.
+type S3SinkOptions struct {
+	Bucket      string
+	EndpointURL string
+	Region      string
+	KeyPrefix   string
+	SSECKey     string
+	Debug       bool
+	DebugHeader string
+}
+
+// S3Sink uploads the local tarball to an S3 (or S3-compatible) bucket under
+// a configurable key prefix, e.g. cluster/<name>/etcd/<ts>.tgz.
+type S3Sink struct {
+	opts   S3SinkOptions
+	client *s3.Client
+}
+
+// NewS3Sink builds an S3Sink from the given options, loading credentials
+// from the standard AWS environment/config chain and optionally overriding
+// the endpoint so MinIO/Ceph RGW deployments work the same as AWS S3.
+func NewS3Sink(ctx context.Context, opts S3SinkOptions) (*S3Sink, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(opts.Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if opts.EndpointURL != "" {
+			o.BaseEndpoint = aws.String(opts.EndpointURL)
+			o.UsePathStyle = true
+		}
+	})
+
+	return &S3Sink{opts: opts, client: client}, nil
+}
+
+// Upload streams the local tarball up to the configured bucket/prefix,
+// applying SSE-C encryption when an SSECKey is configured.
+func (s *S3Sink) Upload(ctx context.Context, localPath string, key string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s for upload: %w", localPath, err)
+	}
+	defer f.Close()
+
+	fullKey := key
+	if s.opts.KeyPrefix != "" {
+		fullKey = s.opts.KeyPrefix + "/" + key
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(s.opts.Bucket),
+		Key:    aws.String(fullKey),
+		Body:   f,
+	}
+	if s.opts.SSECKey != "" {
+		input.SSECustomerAlgorithm = aws.String("AES256")
+		input.SSECustomerKey = aws.String(s.opts.SSECKey)
+	} else {
+		input.ServerSideEncryption = s3types.ServerSideEncryptionAes256
+	}
+
+	if s.opts.Debug {
+		fmt.Printf("%s uploading %s to s3://%s/%s\n", s.opts.DebugHeader, localPath, s.opts.Bucket, fullKey)
+	}
+
+	if _, err := s.client.PutObject(ctx, input); err != nil {
+		return fmt.Errorf("failed to upload %s to s3://%s/%s: %w", localPath, s.opts.Bucket, fullKey, err)
+	}
+	return nil
+}
+
+// PruneOldObjects lists objects under the sink's key prefix and deletes all
+// but the `keep` most recently modified ones, providing a simple retention
+// policy for tarballs shipped to object storage.
+func (s *S3Sink) PruneOldObjects(ctx context.Context, keep int) error {
+	listOutput, err := s.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.opts.Bucket),
+		Prefix: aws.String(s.opts.KeyPrefix),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list objects under s3://%s/%s: %w", s.opts.Bucket, s.opts.KeyPrefix, err)
+	}
+
+	objects := listOutput.Contents
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].LastModified.After(*objects[j].LastModified)
+	})
+
+	if keep < 0 || keep >= len(objects) {
+		return nil
+	}
+
+	for _, obj := range objects[keep:] {
+		if s.opts.Debug {
+			fmt.Printf("%s pruning old backup object s3://%s/%s\n", s.opts.DebugHeader, s.opts.Bucket, *obj.Key)
+		}
+		if _, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(s.opts.Bucket),
+			Key:    obj.Key,
+		}); err != nil {
+			return fmt.Errorf("failed to prune s3://%s/%s: %w", s.opts.Bucket, *obj.Key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	snapshotclientset "github.com/kubernetes-csi/external-snapshotter/client/v6/clientset/versioned"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// runCSISnapshotBackup drives the --snapshot-mode=csi backup path: take a
+// VolumeSnapshot of the backup PVC, wait for it to become ready, then prune
+// old snapshots down to --retain.
+func runCSISnapshotBackup(restConfig *rest.Config, backupProject string, sourcePVCName string, snapshotClassName string, retain int, debug bool, debugHeader string) error {
+	snapshotClient, err := snapshotclientset.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build VolumeSnapshot client: %w", err)
+	}
+
+	snapshotName := fmt.Sprintf("etcd-backup-%s", randomString(8))
+	fmt.Println("Creating VolumeSnapshot of " + sourcePVCName)
+	if _, err := createVolumeSnapshot(backupProject, sourcePVCName, snapshotName, snapshotClassName, debug, debugHeader, snapshotClient); err != nil {
+		return fmt.Errorf("failed to create VolumeSnapshot: %w", err)
+	}
+
+	fmt.Println("Waiting for VolumeSnapshot to become ready...")
+	if err := waitForSnapshotReady(backupProject, snapshotName, debug, debugHeader, snapshotClient); err != nil {
+		return fmt.Errorf("VolumeSnapshot never became ready: %w", err)
+	}
+
+	fmt.Println("Pruning old VolumeSnapshots beyond retention of " + fmt.Sprint(retain))
+	if err := pruneSnapshots(backupProject, "etcd-backup-", retain, debug, debugHeader, snapshotClient); err != nil {
+		return fmt.Errorf("failed to prune old VolumeSnapshots: %w", err)
+	}
+
+	fmt.Println("VolumeSnapshot backup complete: " + snapshotName)
+	return nil
+}
+
+// createVolumeSnapshot takes a CSI VolumeSnapshot of the given PVC using the
+// requested VolumeSnapshotClass. This is the alternative to the oc-debug
+// tarball path for clusters where the CSI driver supports snapshotting.
+func createVolumeSnapshot(namespaceName string, pvcName string, snapshotName string, snapshotClassName string, debug bool, debugHeader string, snapshotClient *snapshotclientset.Clientset) (*snapshotv1.VolumeSnapshot, error) {
+	// createVolumeSnapshot creates a VolumeSnapshot object pointed at the given PVC.
+	// Args:
+	//     namespaceName: the namespace the PVC and snapshot live in
+	//     pvcName: the name of the PVC to snapshot
+	//     snapshotName: the name to give the VolumeSnapshot object
+	//     snapshotClassName: the VolumeSnapshotClass to use
+	//     debug: whether to enable debug logging
+	//     debugHeader: the header to use for debug messages
+	//     snapshotClient: a clientset for the snapshot.storage.k8s.io API group
+	snapshot := &snapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      snapshotName,
+			Namespace: namespaceName,
+		},
+		Spec: snapshotv1.VolumeSnapshotSpec{
+			VolumeSnapshotClassName: &snapshotClassName,
+			Source: snapshotv1.VolumeSnapshotSource{
+				PersistentVolumeClaimName: &pvcName,
+			},
+		},
+	}
+
+	if debug {
+		fmt.Printf("%s creating VolumeSnapshot %s for PVC %s using class %s\n", debugHeader, snapshotName, pvcName, snapshotClassName)
+	}
+
+	created, err := snapshotClient.SnapshotV1().VolumeSnapshots(namespaceName).Create(context.TODO(), snapshot, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create VolumeSnapshot %s: %w", snapshotName, err)
+	}
+	return created, nil
+}
+
+// waitForSnapshotReady polls the VolumeSnapshot until status.readyToUse is
+// true, or returns an error once maxAttempts*pollInterval has elapsed.
+func waitForSnapshotReady(namespaceName string, snapshotName string, debug bool, debugHeader string, snapshotClient *snapshotclientset.Clientset) error {
+	pollInterval := 10 * time.Second
+	maxAttempts := 24
+
+	for i := 0; i < maxAttempts; i++ {
+		snapshot, err := snapshotClient.SnapshotV1().VolumeSnapshots(namespaceName).Get(context.TODO(), snapshotName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get VolumeSnapshot %s: %w", snapshotName, err)
+		}
+
+		if snapshot.Status != nil && snapshot.Status.ReadyToUse != nil && *snapshot.Status.ReadyToUse {
+			if debug {
+				fmt.Printf("%s VolumeSnapshot %s is ready to use\n", debugHeader, snapshotName)
+			}
+			return nil
+		}
+
+		if debug {
+			fmt.Printf("%s VolumeSnapshot %s not ready yet, waited %d seconds\n", debugHeader, snapshotName, i*10)
+		}
+		time.Sleep(pollInterval)
+	}
+
+	return fmt.Errorf("VolumeSnapshot %s did not become ready after %d seconds", snapshotName, maxAttempts*10)
+}
+
+// restoreFromSnapshot provisions a new PVC backed by the given VolumeSnapshot,
+// allowing a point-in-time backup to be mounted back onto a pod for recovery.
+func restoreFromSnapshot(namespaceName string, pvcName string, snapshotName string, volumeSize string, storageClassName string, debug bool, debugHeader string, client kubernetes.Interface) (*corev1.PersistentVolumeClaim, error) {
+	// restoreFromSnapshot creates a PVC with a DataSource pointed at the given VolumeSnapshot.
+	// Args:
+	//     namespaceName: the namespace to restore into
+	//     pvcName: the name of the PVC to create
+	//     snapshotName: the VolumeSnapshot to restore from
+	//     volumeSize: the size to request for the restored PVC
+	//     storageClassName: the storage class to provision the restored PVC from
+	//     debug: whether to enable debug logging
+	//     debugHeader: the header to use for debug messages
+	//     client: a pointer to the Kubernetes client
+	apiGroup := "snapshot.storage.k8s.io"
+	pvcSpec := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pvcName,
+			Namespace: namespaceName,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceName(corev1.ResourceStorage): resource.MustParse(volumeSize),
+				},
+			},
+			StorageClassName: &storageClassName,
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     "VolumeSnapshot",
+				Name:     snapshotName,
+			},
+		},
+	}
+
+	if debug {
+		fmt.Printf("%s restoring PVC %s from VolumeSnapshot %s\n", debugHeader, pvcName, snapshotName)
+	}
+
+	restored, err := client.CoreV1().PersistentVolumeClaims(namespaceName).Create(context.TODO(), pvcSpec, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore PVC %s from snapshot %s: %w", pvcName, snapshotName, err)
+	}
+	return restored, nil
+}
+
+// pruneSnapshots keeps only the `retain` newest VolumeSnapshots with the
+// given name prefix, deleting the rest. This is the GC counterpart to
+// createVolumeSnapshot so old snapshots don't accumulate unbounded.
+func pruneSnapshots(namespaceName string, namePrefix string, retain int, debug bool, debugHeader string, snapshotClient *snapshotclientset.Clientset) error {
+	list, err := snapshotClient.SnapshotV1().VolumeSnapshots(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list VolumeSnapshots: %w", err)
+	}
+
+	var candidates []snapshotv1.VolumeSnapshot
+	for _, snap := range list.Items {
+		if len(snap.Name) >= len(namePrefix) && snap.Name[:len(namePrefix)] == namePrefix {
+			candidates = append(candidates, snap)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].CreationTimestamp.After(candidates[j].CreationTimestamp.Time)
+	})
+
+	if retain < 0 || retain >= len(candidates) {
+		return nil
+	}
+
+	for _, snap := range candidates[retain:] {
+		if debug {
+			fmt.Printf("%s pruning old VolumeSnapshot %s\n", debugHeader, snap.Name)
+		}
+		if err := snapshotClient.SnapshotV1().VolumeSnapshots(namespaceName).Delete(context.TODO(), snap.Name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("failed to prune VolumeSnapshot %s: %w", snap.Name, err)
+		}
+	}
+	return nil
+}
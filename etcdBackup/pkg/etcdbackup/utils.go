@@ -0,0 +1,256 @@
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func pullBackupLocal(nodeName string, localBackupDirectory string, namespaceName string, jobName string, debug bool, debug_header string, kubeconfig string, ocpBinaryPath string, client kubernetes.Interface, sink BackupSink, sinkRetain int, restConfig *rest.Config, legacyOcDebug bool, debugPodImage string, taintName string, serviceAccountName string) error {
+	// pullBackupLocal copies a backup from a remote location to a local directory.
+	// Args:
+	// 		nodeName: the name of the node where the backup is located
+	// 		localBackupDirectory: the path to the local directory where the backup should be copied
+	// 		namespaceName: the name of the namespace where the backup is located
+	// 		jobName: the name of the job that created the backup
+	// 		debug: whether to enable debugging mode
+	// 		debugHeader: the header to use for debugging messages
+	// 		kubeconfig: the path to the Kubernetes configuration file
+	// 		ocpBinaryPath: the path to the OpenShift binary, only used when legacyOcDebug is true
+	// 		client: a pointer to the Kubernetes client
+	// 		sink: where to ship the local tarball after it is written, e.g. local disk or S3
+	// 		sinkRetain: for sinks that support it, how many tarballs to retain (-1 disables pruning)
+	// 		restConfig: the REST config used to open the SPDY exec connection to the debug pod
+	// 		legacyOcDebug: when true, fall back to shelling out to `oc debug node/<x>` instead of a native debug pod
+	// 		debugPodImage, taintName, serviceAccountName: passed through to createDebugPod when legacyOcDebug is false
+	// There may be times where you cannot attach or do not want to attach a PVC
+	// in this case you want to pull the backup locally
+
+	// tarball should be in our temporary location on the control plane host
+	tempTarball := "/host/tmp/etcd_backup.tar.gz"
+	todayDate := fmt.Sprintf("%d-%d-%d_%d_%d_%d", time.Now().Year(), time.Now().Month(), time.Now().Day(), time.Now().Hour(), time.Now().Minute(), time.Now().Second())
+	localTarballLocation := localBackupDirectory + "/etcd_backup_" + todayDate + ".db.tgz"
+
+	fmt.Println("Attempint to copy tarball locally...")
+	if legacyOcDebug {
+		if err := pullBackupLocalViaOcDebug(nodeName, namespaceName, kubeconfig, ocpBinaryPath, tempTarball, localTarballLocation, debug, debug_header); err != nil {
+			return fmt.Errorf("failed to pull backup via oc debug: %w", err)
+		}
+	} else {
+		if err := pullBackupLocalViaDebugPod(restConfig, client, namespaceName, nodeName, debugPodImage, serviceAccountName, taintName, tempTarball, localTarballLocation, debug, debug_header); err != nil {
+			return fmt.Errorf("failed to pull backup via native debug pod: %w", err)
+		}
+	}
+
+	if sink != nil {
+		if uploadErr := sink.Upload(context.TODO(), localTarballLocation, "etcd_backup_"+todayDate+".db.tgz"); uploadErr != nil {
+			return fmt.Errorf("failed to upload backup to sink: %w", uploadErr)
+		}
+		if s3Sink, ok := sink.(*S3Sink); ok && sinkRetain >= 0 {
+			if pruneErr := s3Sink.PruneOldObjects(context.TODO(), sinkRetain); pruneErr != nil {
+				return fmt.Errorf("failed to prune old backups from sink: %w", pruneErr)
+			}
+		}
+	}
+
+	fmt.Println("Starting cleanup")
+	if legacyOcDebug {
+		cmd := ocDebugPrefix(kubeconfig, ocpBinaryPath, nodeName)
+		cleanupCMD := cmd + " -- rm -fv " + tempTarball
+		if debug {
+			fmt.Printf("%s using the following cleanup command:\n\t\t\t  %s\n", debug_header, cleanupCMD)
+		}
+		out2, _ := exec.Command("sh", "-c", cleanupCMD).CombinedOutput()
+		fmt.Println(string(out2))
+	} else {
+		podName := debugPodName(nodeName)
+		if _, _, err := execInDebugPod(restConfig, client, namespaceName, podName, []string{"rm", "-fv", tempTarball}); err != nil && debug {
+			fmt.Printf("%s failed to clean up %s on debug pod %s: %s\n", debug_header, tempTarball, podName, err)
+		}
+		if err := deleteDebugPod(namespaceName, podName, client); err != nil && debug {
+			fmt.Printf("%s failed to delete debug pod %s: %s\n", debug_header, podName, err)
+		}
+	}
+	return nil
+}
+
+// ocDebugPrefix builds the `oc debug node/<x>` command prefix used by the
+// legacy --legacy-oc-debug path.
+func ocDebugPrefix(kubeconfig string, ocpBinaryPath string, nodeName string) string {
+	if ocpBinaryPath == "" {
+		return fmt.Sprintf("KUBECONFIG=%s oc debug node/%s", kubeconfig, nodeName)
+	}
+	return fmt.Sprintf("KUBECONFIG=%s %s/oc debug node/%s", kubeconfig, ocpBinaryPath, nodeName)
+}
+
+// pullBackupLocalViaOcDebug is the original tarball-pull path that shells
+// out to `oc debug node/<x> -- cat <tarball>`. Kept behind --legacy-oc-debug
+// for clusters/users that still want the oc binary in the loop.
+func pullBackupLocalViaOcDebug(nodeName string, namespaceName string, kubeconfig string, ocpBinaryPath string, tempTarball string, localTarballLocation string, debug bool, debug_header string) error {
+	cmd := ocDebugPrefix(kubeconfig, ocpBinaryPath, nodeName)
+	catCMD := cmd + " -- cat " + tempTarball
+	// this is a hack to get around the error "arguments in resource/name form may not have more than one slash"
+	// seems to be some weird escaping happening in the exec command
+	if debug {
+		fmt.Printf("%s running the following command \n\t\t\t%s\n", debug_header, catCMD)
+	}
+	output, catTarballError := exec.Command("sh", "-c", catCMD).Output()
+	if catTarballError != nil {
+		return fmt.Errorf("failed to read remote file: %w", catTarballError)
+	}
+
+	f, createLocalFileError := os.Create(localTarballLocation)
+	if createLocalFileError != nil {
+		return fmt.Errorf("failed to create local file %s: %w", localTarballLocation, createLocalFileError)
+	}
+	defer f.Close()
+
+	if _, saveFileError := f.Write(output); saveFileError != nil {
+		return fmt.Errorf("failed to save local file %s: %w", localTarballLocation, saveFileError)
+	}
+	return nil
+}
+
+// pullBackupLocalViaDebugPod streams the tarball straight over the
+// Kubernetes API exec connection from a native privileged debug pod,
+// removing the dependency on the oc binary entirely.
+func pullBackupLocalViaDebugPod(restConfig *rest.Config, client kubernetes.Interface, namespaceName string, nodeName string, debugPodImage string, serviceAccountName string, taintName string, tempTarball string, localTarballLocation string, debug bool, debug_header string) error {
+	taintKey := taintName
+	taintVal := ""
+	if strings.Contains(taintName, "=") {
+		splitVar := strings.Split(taintName, "=")
+		taintKey = splitVar[0]
+		taintVal = splitVar[1]
+	}
+
+	pod, err := createDebugPod(namespaceName, nodeName, debugPodImage, serviceAccountName, taintKey, taintVal, debug, debug_header, client)
+	if err != nil {
+		return err
+	}
+
+	if err := waitForDebugPodRunning(namespaceName, pod.Name, debug, debug_header, client); err != nil {
+		return err
+	}
+
+	f, createLocalFileError := os.Create(localTarballLocation)
+	if createLocalFileError != nil {
+		return fmt.Errorf("failed to create local file %s: %w", localTarballLocation, createLocalFileError)
+	}
+	defer f.Close()
+
+	if debug {
+		fmt.Printf("%s streaming %s from debug pod %s\n", debug_header, tempTarball, pod.Name)
+	}
+	return streamTarballFromDebugPod(restConfig, client, namespaceName, pod.Name, tempTarball, f)
+}
+
+// waitForJobComplete waits for the backup job to complete before attempting
+// to copy the tarball locally. It watches the Job rather than sleeping on a
+// fixed tick, so it reacts on the next status event, and falls back to an
+// exponential-backoff poll if the watch is dropped. ctx controls cancellation
+// (e.g. Ctrl-C) and the overall timeout.
+// Args:
+// 		ctx: context controlling cancellation and timeout
+// 		namespaceName: the name of the namespace where the backup job is located
+// 		jobName: the name of the backup job
+// 		debug: whether to enable debugging mode
+// 		debugHeader: the header to use for debugging messages
+// 		nodeName: the name of the node where the backup job is running
+// 		pollInterval: the base interval used by the exponential-backoff fallback poll
+// 		client: a pointer to the Kubernetes client
+// It returns nil on success, or ErrJobFailed/ErrTimeout/ctx.Err() otherwise.
+func waitForJobComplete(ctx context.Context, namespaceName string, jobName string, debug bool, debug_header string, nodeName string, pollInterval time.Duration, client kubernetes.Interface) error {
+	watcher, watchErr := client.BatchV1().Jobs(namespaceName).Watch(ctx, metav1.ListOptions{
+		FieldSelector: "metadata.name=" + jobName,
+	})
+	if watchErr != nil {
+		if debug {
+			fmt.Printf("%s failed to watch job %s, falling back to polling: %s\n", debug_header, jobName, watchErr)
+		}
+		return pollForJobComplete(ctx, namespaceName, jobName, debug, debug_header, pollInterval, client)
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return pollForJobComplete(ctx, namespaceName, jobName, debug, debug_header, pollInterval, client)
+			}
+			job, isJob := event.Object.(*batchv1.Job)
+			if !isJob {
+				continue
+			}
+			if debug {
+				fmt.Printf("%s job %s active=%d succeeded=%d failed=%d\n", debug_header, job.Name, job.Status.Active, job.Status.Succeeded, job.Status.Failed)
+			}
+			if job.Status.Succeeded > 0 {
+				return nil
+			}
+			if job.Status.Failed > 0 {
+				return ErrJobFailed
+			}
+		}
+	}
+}
+
+// pollForJobComplete is the ExponentialBackoffWithContext fallback used when
+// the Job watch cannot be established or is dropped mid-wait.
+func pollForJobComplete(ctx context.Context, namespaceName string, jobName string, debug bool, debug_header string, pollInterval time.Duration, client kubernetes.Interface) error {
+	backoff := wait.Backoff{
+		Duration: pollInterval,
+		Factor:   1.5,
+		Jitter:   0.1,
+		Steps:    12,
+	}
+
+	var lastErr error
+	err := wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		job, getJobError := client.BatchV1().Jobs(namespaceName).Get(ctx, jobName, metav1.GetOptions{})
+		if getJobError != nil {
+			return false, getJobError
+		}
+		if debug {
+			fmt.Printf("%s job %s active=%d succeeded=%d failed=%d\n", debug_header, job.Name, job.Status.Active, job.Status.Succeeded, job.Status.Failed)
+		}
+		if job.Status.Succeeded > 0 {
+			return true, nil
+		}
+		if job.Status.Failed > 0 {
+			lastErr = ErrJobFailed
+			return false, ErrJobFailed
+		}
+		return false, nil
+	})
+	if lastErr != nil {
+		return lastErr
+	}
+	if err != nil {
+		if err == wait.ErrWaitTimeout {
+			return ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+func randomString(length int) string {
+	// Generate a random uuid to attach to the pod name
+	// so that this can be called multiple times without conflicting with previous runs
+	rand.Seed(time.Now().UnixNano())
+	b := make([]byte, length)
+	rand.Read(b)
+	return fmt.Sprintf("%x", b)[:length]
+}
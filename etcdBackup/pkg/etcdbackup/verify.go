@@ -0,0 +1,193 @@
+package etcdbackup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// backupJobOptions bundles everything runBackupJob needs to create a backup
+// Job, wait for it, and verify the tarball it produced. It is populated by
+// Run from the caller-facing Options.
+type backupJobOptions struct {
+	Client             kubernetes.Interface
+	Namespace          string
+	Node               string
+	ImageURL           string
+	ServiceAccountName string
+	JobName            string
+	BackupJob          *batchv1.Job
+	// PVCName is the PVC the backup tarball lands on. Empty skips
+	// post-backup verification, since there is nowhere to read the
+	// just-written tarball back from.
+	PVCName      string
+	PollInterval time.Duration
+	Debug        bool
+	DebugHeader  string
+}
+
+// etcdctlSnapshotStatus mirrors the fields of `etcdctl snapshot status
+// --write-out=json`.
+type etcdctlSnapshotStatus struct {
+	Hash      uint32 `json:"hash"`
+	Revision  int64  `json:"revision"`
+	TotalKey  int64  `json:"totalKey"`
+	TotalSize int64  `json:"totalSize"`
+}
+
+const (
+	etcdRevisionAnnotation = "backup.stratus.io/etcd-revision"
+	snapshotHashAnnotation = "backup.stratus.io/snapshot-hash"
+)
+
+// runBackupJob creates opts.BackupJob and waits for it to complete. When
+// opts.PVCName is set, it then verifies the resulting tarball with a second,
+// short-lived Job running `etcdctl snapshot status`, failing the run if the
+// tarball cannot be opened or its hash comes back empty, instead of the
+// previous behavior of reporting "Backup job complete" regardless.
+func runBackupJob(ctx context.Context, opts backupJobOptions) (*Result, error) {
+	if _, err := opts.Client.BatchV1().Jobs(opts.Namespace).Create(ctx, opts.BackupJob, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to create backup job: %w", err)
+	}
+
+	if err := waitForJobComplete(ctx, opts.Namespace, opts.JobName, opts.Debug, opts.DebugHeader, opts.Node, opts.PollInterval, opts.Client); err != nil {
+		return nil, fmt.Errorf("backup job did not complete successfully: %w", err)
+	}
+
+	if opts.PVCName == "" {
+		return &Result{JobName: opts.JobName}, nil
+	}
+
+	result, err := verifyBackupSnapshot(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("backup tarball failed verification: %w", err)
+	}
+
+	if err := annotateBackupJob(ctx, opts.Namespace, opts.JobName, result, opts.Client); err != nil {
+		return result, fmt.Errorf("backup verified but failed to annotate job %s: %w", opts.JobName, err)
+	}
+
+	return result, nil
+}
+
+// verifyBackupSnapshot runs a short-lived Job on opts.Node that extracts the
+// most recently written backup_*.db.tgz from the PVC and runs `etcdctl
+// snapshot status` against it, returning the parsed result.
+func verifyBackupSnapshot(ctx context.Context, opts backupJobOptions) (*Result, error) {
+	verifyJobName := opts.JobName + "-verify"
+	verifyCmd := "LATEST=$(ls -1 /backups/backup_*.db.tgz 2>/dev/null | sort | tail -n1) && " +
+		"mkdir -p /tmp/verify && tar xzf \"$LATEST\" -C /tmp/verify && " +
+		"DBFILE=$(find /tmp/verify -name '*.db' | head -n1) && " +
+		"etcdctl snapshot status \"$DBFILE\" --write-out=json"
+
+	backoffLimit := int32(0)
+	verifyJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      verifyJobName,
+			Namespace: opts.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            verifyJobName,
+							Image:           opts.ImageURL,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"/bin/bash", "-c", verifyCmd},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "etcd-backup-mount", MountPath: "/backups"},
+							},
+						},
+					},
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: opts.ServiceAccountName,
+					Volumes: []corev1.Volume{
+						{
+							Name: "etcd-backup-mount",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: opts.PVCName},
+							},
+						},
+					},
+					NodeSelector: map[string]string{
+						"node-role.kubernetes.io/master": "",
+					},
+				},
+			},
+		},
+	}
+
+	if _, err := opts.Client.BatchV1().Jobs(opts.Namespace).Create(ctx, verifyJob, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to create verification job: %w", err)
+	}
+	defer func() {
+		background := metav1.DeletePropagationBackground
+		_ = opts.Client.BatchV1().Jobs(opts.Namespace).Delete(context.Background(), verifyJobName, metav1.DeleteOptions{PropagationPolicy: &background})
+	}()
+
+	if err := waitForJobComplete(ctx, opts.Namespace, verifyJobName, opts.Debug, opts.DebugHeader, opts.Node, opts.PollInterval, opts.Client); err != nil {
+		return nil, fmt.Errorf("snapshot status job failed, tarball may be corrupt: %w", err)
+	}
+
+	logs, err := readJobLogs(ctx, opts.Client, opts.Namespace, verifyJobName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verification job logs: %w", err)
+	}
+
+	var status etcdctlSnapshotStatus
+	if err := json.Unmarshal(logs, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse etcdctl snapshot status output: %w", err)
+	}
+	if status.Hash == 0 {
+		return nil, fmt.Errorf("etcdctl snapshot status reported an empty hash, tarball is likely corrupt")
+	}
+
+	return &Result{
+		JobName:        opts.JobName,
+		Hash:           fmt.Sprintf("%d", status.Hash),
+		Revision:       status.Revision,
+		TotalKeys:      status.TotalKey,
+		TotalSizeBytes: status.TotalSize,
+	}, nil
+}
+
+// readJobLogs returns the combined stdout/stderr of the (single, Never
+// restarted) pod owned by the given Job.
+func readJobLogs(ctx context.Context, client kubernetes.Interface, namespaceName string, jobName string) ([]byte, error) {
+	pods, err := client.CoreV1().Pods(namespaceName).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + jobName})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no pod found for job %s", jobName)
+	}
+
+	stream, err := client.CoreV1().Pods(namespaceName).GetLogs(pods.Items[0].Name, &corev1.PodLogOptions{}).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return io.ReadAll(stream)
+}
+
+// annotateBackupJob records the verified snapshot's revision and hash on
+// the backup Job object so they can be read back with `kubectl get job -o
+// jsonpath` without parsing logs.
+func annotateBackupJob(ctx context.Context, namespaceName string, jobName string, result *Result, client kubernetes.Interface) error {
+	patch := []byte(fmt.Sprintf(
+		`{"metadata":{"annotations":{%q:"%d",%q:%q}}}`,
+		etcdRevisionAnnotation, result.Revision, snapshotHashAnnotation, result.Hash,
+	))
+	_, err := client.BatchV1().Jobs(namespaceName).Patch(ctx, jobName, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
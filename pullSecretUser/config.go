@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// IdentityConfig describes a single credential identity to hunt for across the
+// cluster. A secret is considered a match if any of the Usernames, Registries
+// or Patterns fields matches something found inside it.
+type IdentityConfig struct {
+	Name       string   `json:"name"`
+	Usernames  []string `json:"usernames,omitempty"`
+	Registries []string `json:"registries,omitempty"`
+	Patterns   []string `json:"patterns,omitempty"`
+}
+
+// ScanConfig is the top level structure of the `--config` file passed to the
+// scanner. It allows a single run to hunt for many identities at once instead
+// of the single `--service-account` flag supported previously.
+type ScanConfig struct {
+	Identities []IdentityConfig `json:"identities"`
+}
+
+// compiledIdentity is a ScanConfig entry with its regex patterns pre-compiled
+// so the worker pool isn't recompiling them per secret.
+type compiledIdentity struct {
+	name       string
+	usernames  []string
+	registries []string
+	patterns   []*regexp.Regexp
+}
+
+// loadScanConfig reads and parses the identities config file from disk.
+func loadScanConfig(configPath string) (*ScanConfig, error) {
+	raw, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", configPath, err)
+	}
+	var cfg ScanConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+	return &cfg, nil
+}
+
+// compileIdentities pre-compiles the regex patterns in a ScanConfig so they
+// can be shared read-only across worker goroutines.
+func compileIdentities(cfg *ScanConfig) ([]compiledIdentity, error) {
+	compiled := make([]compiledIdentity, 0, len(cfg.Identities))
+	for _, identity := range cfg.Identities {
+		patterns := make([]*regexp.Regexp, 0, len(identity.Patterns))
+		for _, p := range identity.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("identity %s: invalid pattern %q: %w", identity.Name, p, err)
+			}
+			patterns = append(patterns, re)
+		}
+		compiled = append(compiled, compiledIdentity{
+			name:       identity.Name,
+			usernames:  identity.Usernames,
+			registries: identity.Registries,
+			patterns:   patterns,
+		})
+	}
+	return compiled, nil
+}
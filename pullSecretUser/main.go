@@ -5,102 +5,141 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"strings"
+	"os"
+	"regexp"
+	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"gopkg.in/yaml.v2"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-// This program will crawl the projects in a cluster and retrieve any secrets
-// which have the a specified username inside them (generally a pull secret)
-// this can be very slow as it introspects all of the secrets in the cluster
+// This program crawls the secrets in a cluster and reports any that contain
+// credentials matching a configured set of identities (usernames, registries
+// or regex patterns). It streams secrets via a shared informer rather than
+// issuing a per-secret Get, and fans the matching work out across a worker
+// pool so cluster-wide credential audits stay tractable on large clusters.
 
 func main() {
-	// Get the command line arguments from the user
-	serviceAccountName := flag.String("service-account", "deployer", "The name of the service account to find.")
 	kubeConfigFile := flag.String("kube-config", "", "Full path to kubeconfig")
-	firstDataType := flag.String("first-data-type", "dockerconfigjson", "The heading of the in the 'data' section of the secret you wish to inspect")
-	secondDataType := flag.String("second-data-type", "", "The heading of the in the 'data' section of the secret you wish to inspect")
-	ignoreOpenShiftProjects := flag.Bool("ignore-openshift", true, "Ignores the Openshift-* projects to speed things up")
+	configPath := flag.String("config", "", "Path to a JSON identities config file describing usernames/registries/patterns to hunt for")
+	serviceAccountName := flag.String("service-account", "", "Shorthand for a single username identity, kept for backwards compatibility with the old --service-account flag")
+	workers := flag.Int("workers", 4, "Number of concurrent workers used to match streamed secrets")
+	includeNamespace := flag.String("include-namespace", "", "Regex of namespaces to include, applied before --exclude-namespace")
+	excludeNamespace := flag.String("exclude-namespace", "^openshift", "Regex of namespaces to exclude")
+	outputFormat := flag.String("output-format", "json", "Format for emitted findings: json or yaml")
+	matchersFlag := flag.String("matchers", "", "Comma-separated list of matchers to run: dockerconfig,basicauth,ssh,opaque. Empty runs all of them")
+	redact := flag.Bool("redact", false, "Hash passwords/evidence with sha256 instead of printing them in plaintext")
 	debug := flag.Bool("debug", false, "Turns on some debug messages")
 	flag.Parse()
 
 	debugHeader := "\n(( DEBUG )) -->"
 
-	// If no kubeconfig is passed in, attempt to find it in a default location
 	if *kubeConfigFile == "" {
 		*kubeConfigFile = "${USER}/.kube/auth/kubeconfig"
 		fmt.Println("No kubeconfig attempting to use ~/.kube/auth/kubeconfig")
 	}
 
-	config, err := clientcmd.BuildConfigFromFlags("", *kubeConfigFile)
+	cfg, err := buildScanConfig(*configPath, *serviceAccountName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
+	identities, err := compileIdentities(cfg)
 	if err != nil {
-		panic(err)
+		fmt.Println(err)
+		os.Exit(1)
 	}
-	client, _ := kubernetes.NewForConfig(config)
-	// get all the namespaces so that we can loop over the secrets in that project
-	namespaces, _ := client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
 
-	for _, projectInfo := range namespaces.Items {
-		// get all the secrets in the current namespace
-		if *debug != false {
-			fmt.Printf("%s Project is: %s", debugHeader, projectInfo.Name)
+	var includeRe, excludeRe *regexp.Regexp
+	if *includeNamespace != "" {
+		includeRe, err = regexp.Compile(*includeNamespace)
+		if err != nil {
+			fmt.Printf("invalid --include-namespace pattern: %s\n", err)
+			os.Exit(1)
 		}
-		if *ignoreOpenShiftProjects == true && strings.Contains(projectInfo.Name, "openshift") {
-			continue
+	}
+	if *excludeNamespace != "" {
+		excludeRe, err = regexp.Compile(*excludeNamespace)
+		if err != nil {
+			fmt.Printf("invalid --exclude-namespace pattern: %s\n", err)
+			os.Exit(1)
 		}
-		all_secrets, _ := client.CoreV1().Secrets(projectInfo.Name).List(context.TODO(), metav1.ListOptions{})
-		for _, secretsInfo := range all_secrets.Items {
-			if *debug != false {
-				fmt.Printf("%s      Secret is: %s", debugHeader, secretsInfo.Name)
-			}
-			individual_secret, _ := client.CoreV1().Secrets(projectInfo.Name).Get(context.TODO(), secretsInfo.Name, metav1.GetOptions{})
-
-			for secretsKey, secretValue := range individual_secret.Data {
-				if strings.Contains(secretsKey, *firstDataType) || strings.Contains(secretsKey, *secondDataType) {
-					var result map[string]interface{}
-					json.Unmarshal([]byte(secretValue), &result)
-					// json structure {"auths":{"<repo>":{"username":"faker","password":"snoogy","email":"admin@me.com","auth":"ZmF2d5"}}}
-					// Some maps may be empty, we want to ignore them as they wont have the keys we are looking for
-					auths, ok := result["auths"].(map[string]interface{})
-
-					if !ok {
-						if *debug != false {
-							fmt.Printf("%s   WARNING!!  %s   has unexpected format", debugHeader, secretsInfo.Name)
-						}
-					}
-
-					for _, val := range auths {
-						unknownRepo, ok := val.(map[string]interface{})
-						if !ok {
-							if *debug != false {
-								fmt.Printf("%s   WARNING!!  %s   has unexpected format", debugHeader, secretsInfo.Name)
-							}
-						}
-						var foundUsername string
-						var password string
-						for authHeadings, authValues := range unknownRepo {
-							if strings.Contains(authHeadings, "username") {
-								unknownUser := fmt.Sprintf("%v", authValues)
-								if strings.ToLower(unknownUser) == strings.ToLower(*serviceAccountName) {
-									foundUsername = unknownUser
-								}
-							}
-							if strings.Contains(authHeadings, "password") {
-								password = fmt.Sprintf("%v", authValues)
-							}
-
-						}
-						if len(foundUsername) != 0 {
-							fmt.Printf("\n\nSecret Name: %s \n   Project Name: %s \n   Username: %s \n   Password %s\n", secretsInfo.Name, projectInfo.Name, foundUsername, password)
-						}
-
-					}
-				}
-			}
+	}
+
+	matchers, err := selectMatchers(*matchersFlag)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", *kubeConfigFile)
+	if err != nil {
+		panic(err)
+	}
+	client, _ := kubernetes.NewForConfig(config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	findings, err := RunScanner(ctx, ScannerOptions{
+		Client:           client,
+		Identities:       identities,
+		Matchers:         matchers,
+		Redact:           *redact,
+		Workers:          *workers,
+		IncludeNamespace: includeRe,
+		ExcludeNamespace: excludeRe,
+		ResyncInterval:   10 * time.Minute,
+		Debug:            *debug,
+		DebugHeader:      debugHeader,
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	for finding := range findings {
+		emitFinding(finding, *outputFormat)
+	}
+}
 
+// buildScanConfig loads the identities config file if one was passed in,
+// otherwise falls back to a single identity built from --service-account so
+// existing invocations of this tool keep working.
+func buildScanConfig(configPath string, serviceAccountName string) (*ScanConfig, error) {
+	if configPath != "" {
+		return loadScanConfig(configPath)
+	}
+	if serviceAccountName == "" {
+		serviceAccountName = "deployer"
+	}
+	return &ScanConfig{
+		Identities: []IdentityConfig{
+			{Name: serviceAccountName, Usernames: []string{serviceAccountName}},
+		},
+	}, nil
+}
+
+// emitFinding writes a single Finding record to stdout in the requested
+// format, one record per match so the output can be piped into other tools.
+func emitFinding(finding Finding, format string) {
+	switch format {
+	case "yaml":
+		out, err := yaml.Marshal(finding)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("---")
+		fmt.Print(string(out))
+	default:
+		out, err := json.Marshal(finding)
+		if err != nil {
+			fmt.Println(err)
+			return
 		}
+		fmt.Println(string(out))
 	}
 }
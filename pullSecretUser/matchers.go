@@ -0,0 +1,224 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SecretMatcher inspects a single Secret's Data against the configured
+// identities and returns one Finding per hit. This replaces the hard-coded
+// auths[*].username/password walk with a pluggable pipeline so new secret
+// shapes can be added without touching the scanner itself.
+type SecretMatcher interface {
+	// Type is the matcher name used in --matchers and in Finding.Type.
+	Type() string
+	// Match inspects secret and returns any Findings against identities.
+	Match(secret *corev1.Secret, identities []compiledIdentity, redact bool) []Finding
+}
+
+// availableMatchers is the registry of matchers this tool knows how to run,
+// keyed by the name used in --matchers.
+func availableMatchers() map[string]SecretMatcher {
+	return map[string]SecretMatcher{
+		"dockerconfig": dockerConfigMatcher{},
+		"basicauth":    basicAuthMatcher{},
+		"ssh":          sshAuthMatcher{},
+		"opaque":       opaqueMatcher{},
+	}
+}
+
+// selectMatchers resolves a comma-separated --matchers flag value into the
+// SecretMatcher implementations to run. An empty spec runs all matchers.
+func selectMatchers(spec string) ([]SecretMatcher, error) {
+	all := availableMatchers()
+	if spec == "" {
+		matchers := make([]SecretMatcher, 0, len(all))
+		for _, m := range all {
+			matchers = append(matchers, m)
+		}
+		return matchers, nil
+	}
+
+	var matchers []SecretMatcher
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		m, ok := all[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown matcher %q, available: dockerconfig, basicauth, ssh, opaque", name)
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// redactEvidence hashes sensitive evidence with sha256 when redact is true,
+// rather than emitting it as plaintext.
+func redactEvidence(value string, redact bool) string {
+	if !redact || value == "" {
+		return value
+	}
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// dockerConfigMatcher handles both the modern `kubernetes.io/dockerconfigjson`
+// format and the legacy single-level `kubernetes.io/dockercfg` format.
+type dockerConfigMatcher struct{}
+
+func (dockerConfigMatcher) Type() string { return "dockerconfig" }
+
+func (dockerConfigMatcher) Match(secret *corev1.Secret, identities []compiledIdentity, redact bool) []Finding {
+	var findings []Finding
+
+	type authEntry struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	addFindings := func(registry string, entry authEntry) {
+		for _, identity := range identities {
+			if identityMatches(identity, registry, entry.Username) {
+				findings = append(findings, Finding{
+					Namespace: secret.Namespace,
+					Secret:    secret.Name,
+					Type:      "dockerconfig",
+					Identity:  identity.name,
+					Evidence:  fmt.Sprintf("registry=%s username=%s password=%s", registry, entry.Username, redactEvidence(entry.Password, redact)),
+				})
+			}
+		}
+	}
+
+	if raw, ok := firstPresent(secret.Data, ".dockerconfigjson", "dockerconfigjson"); ok {
+		var payload struct {
+			Auths map[string]authEntry `json:"auths"`
+		}
+		if err := json.Unmarshal(raw, &payload); err == nil {
+			for registry, entry := range payload.Auths {
+				addFindings(registry, entry)
+			}
+		}
+	}
+
+	if raw, ok := firstPresent(secret.Data, ".dockercfg", "dockercfg"); ok {
+		var payload map[string]authEntry
+		if err := json.Unmarshal(raw, &payload); err == nil {
+			for registry, entry := range payload {
+				addFindings(registry, entry)
+			}
+		}
+	}
+
+	return findings
+}
+
+// basicAuthMatcher handles `kubernetes.io/basic-auth` secrets, which carry
+// plain `username`/`password` keys rather than a per-registry auths map.
+type basicAuthMatcher struct{}
+
+func (basicAuthMatcher) Type() string { return "basicauth" }
+
+func (basicAuthMatcher) Match(secret *corev1.Secret, identities []compiledIdentity, redact bool) []Finding {
+	if secret.Type != corev1.SecretTypeBasicAuth {
+		return nil
+	}
+	username := string(secret.Data[corev1.BasicAuthUsernameKey])
+	password := string(secret.Data[corev1.BasicAuthPasswordKey])
+
+	var findings []Finding
+	for _, identity := range identities {
+		if identityMatches(identity, "", username) {
+			findings = append(findings, Finding{
+				Namespace: secret.Namespace,
+				Secret:    secret.Name,
+				Type:      "basicauth",
+				Identity:  identity.name,
+				Evidence:  fmt.Sprintf("username=%s password=%s", username, redactEvidence(password, redact)),
+			})
+		}
+	}
+	return findings
+}
+
+// sshAuthMatcher handles `kubernetes.io/ssh-auth` secrets. Rather than
+// surfacing the private key material, it reports the key's fingerprint so
+// findings can be correlated without leaking the key itself.
+type sshAuthMatcher struct{}
+
+func (sshAuthMatcher) Type() string { return "ssh" }
+
+func (sshAuthMatcher) Match(secret *corev1.Secret, identities []compiledIdentity, redact bool) []Finding {
+	if secret.Type != corev1.SecretTypeSSHAuth {
+		return nil
+	}
+	keyBytes := secret.Data[corev1.SSHAuthPrivateKey]
+	if len(keyBytes) == 0 {
+		return nil
+	}
+
+	signer, err := ssh.ParsePrivateKey(keyBytes)
+	if err != nil {
+		return nil
+	}
+	fingerprint := ssh.FingerprintSHA256(signer.PublicKey())
+
+	var findings []Finding
+	for _, identity := range identities {
+		if identityMatches(identity, "", secret.Name) {
+			findings = append(findings, Finding{
+				Namespace: secret.Namespace,
+				Secret:    secret.Name,
+				Type:      "ssh",
+				Identity:  identity.name,
+				Evidence:  "fingerprint=" + fingerprint,
+			})
+		}
+	}
+	return findings
+}
+
+// opaqueMatcher handles `Opaque` secrets by regex-matching each identity's
+// patterns directly against every key/value pair.
+type opaqueMatcher struct{}
+
+func (opaqueMatcher) Type() string { return "opaque" }
+
+func (opaqueMatcher) Match(secret *corev1.Secret, identities []compiledIdentity, redact bool) []Finding {
+	if secret.Type != corev1.SecretTypeOpaque {
+		return nil
+	}
+
+	var findings []Finding
+	for key, value := range secret.Data {
+		for _, identity := range identities {
+			for _, pattern := range identity.patterns {
+				if pattern.MatchString(key) || pattern.MatchString(string(value)) {
+					findings = append(findings, Finding{
+						Namespace: secret.Namespace,
+						Secret:    secret.Name,
+						Type:      "opaque",
+						Identity:  identity.name,
+						Evidence:  fmt.Sprintf("key=%s value=%s", key, redactEvidence(string(value), redact)),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// firstPresent returns the first of the candidate keys found in data.
+func firstPresent(data map[string][]byte, keys ...string) ([]byte, bool) {
+	for _, key := range keys {
+		if raw, ok := data[key]; ok {
+			return raw, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// newSecretInformerFactory builds a cluster-wide (all-namespaces) shared
+// informer factory scoped to v1.Secret so the scanner can list/watch instead
+// of issuing a per-secret Get.
+func newSecretInformerFactory(client kubernetes.Interface, resync time.Duration) informers.SharedInformerFactory {
+	return informers.NewSharedInformerFactory(client, resync)
+}
+
+// Finding is a single match emitted by the scanner, one record per secret
+// that satisfied an identity in the scan config.
+type Finding struct {
+	Namespace string `json:"namespace"`
+	Secret    string `json:"secret"`
+	Type      string `json:"type"`
+	Identity  string `json:"identity"`
+	Evidence  string `json:"evidence"`
+}
+
+// ScannerOptions controls the informer-based scan.
+type ScannerOptions struct {
+	Client           kubernetes.Interface
+	Identities       []compiledIdentity
+	Matchers         []SecretMatcher
+	Redact           bool
+	Workers          int
+	IncludeNamespace *regexp.Regexp
+	ExcludeNamespace *regexp.Regexp
+	ResyncInterval   time.Duration
+	Debug            bool
+	DebugHeader      string
+}
+
+// RunScanner streams secrets cluster-wide via a shared informer (instead of
+// the previous namespace->secret->Get loop, which issues one GET per secret)
+// and fans the matching work out across a worker pool. Findings are sent to
+// the returned channel as they are discovered; the channel is closed once the
+// informer's initial list has been fully processed.
+func RunScanner(ctx context.Context, opts ScannerOptions) (<-chan Finding, error) {
+	if opts.Workers <= 0 {
+		opts.Workers = 4
+	}
+
+	secretQueue := make(chan *corev1.Secret, opts.Workers*4)
+	findings := make(chan Finding, opts.Workers*4)
+
+	factory := newSecretInformerFactory(opts.Client, opts.ResyncInterval)
+	informer := factory.Core().V1().Secrets().Informer()
+
+	var wg sync.WaitGroup
+	wg.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go func() {
+			defer wg.Done()
+			for secret := range secretQueue {
+				matchSecret(secret, opts, findings)
+			}
+		}()
+	}
+
+	handle, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			secret, ok := obj.(*corev1.Secret)
+			if !ok {
+				return
+			}
+			if !namespaceAllowed(secret.Namespace, opts.IncludeNamespace, opts.ExcludeNamespace) {
+				return
+			}
+			secretQueue <- secret
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register secret event handler: %w", err)
+	}
+
+	synced := make(chan struct{})
+	go informer.Run(ctx.Done())
+	go func() {
+		if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+			if opts.Debug {
+				fmt.Printf("%s informer cache sync was cancelled before completing\n", opts.DebugHeader)
+			}
+		}
+		// Stop delivering new secrets to secretQueue before closing it: the
+		// informer itself keeps running for the life of ctx, and a Secret
+		// created after this point would otherwise panic on a send to a
+		// closed channel.
+		if err := informer.RemoveEventHandler(handle); err != nil && opts.Debug {
+			fmt.Printf("%s failed to remove secret event handler: %s\n", opts.DebugHeader, err)
+		}
+		close(synced)
+	}()
+
+	go func() {
+		<-synced
+		close(secretQueue)
+		wg.Wait()
+		close(findings)
+	}()
+
+	return findings, nil
+}
+
+func namespaceAllowed(namespace string, include, exclude *regexp.Regexp) bool {
+	if exclude != nil && exclude.MatchString(namespace) {
+		return false
+	}
+	if include != nil && !include.MatchString(namespace) {
+		return false
+	}
+	return true
+}
+
+// matchSecret runs every configured matcher against a single secret and
+// emits a Finding for each hit.
+func matchSecret(secret *corev1.Secret, opts ScannerOptions, findings chan<- Finding) {
+	for _, matcher := range opts.Matchers {
+		for _, finding := range matcher.Match(secret, opts.Identities, opts.Redact) {
+			findings <- finding
+		}
+	}
+}
+
+func identityMatches(identity compiledIdentity, registry string, username string) bool {
+	for _, u := range identity.usernames {
+		if strings.EqualFold(u, username) {
+			return true
+		}
+	}
+	for _, r := range identity.registries {
+		if strings.EqualFold(r, registry) {
+			return true
+		}
+	}
+	for _, p := range identity.patterns {
+		if p.MatchString(username) || p.MatchString(registry) {
+			return true
+		}
+	}
+	return false
+}